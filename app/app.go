@@ -1,13 +1,22 @@
 package app
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"sync/atomic"
+	"time"
 
+	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 type App struct {
+	// tmux backend used by all the views
+	backend Backend
+
 	// panel, preview and tree views
 	preview *Preview
 	panel   *Panel
@@ -15,14 +24,108 @@ type App struct {
 
 	// ui instance, tview app
 	ui *UI
+
+	// tabs toggling between the tree and the panel
+	tabs *tview.Pages
+
+	// set while an automatic refresh is queued
+	refreshing atomic.Bool
+
+	// set while the control mode client is attached
+	controlActive atomic.Bool
+
+	// set while a preview capture is scheduled
+	capturing atomic.Bool
+
+	// set when running in the tmuxman popup, which closes after switching sessions
+	inPopup bool
+
+	// set to save the scrollback of the panes along with the sessions
+	scrollback bool
+
+	// names of the actions of the keybindings, and the conflicts between their keys
+	actions map[string]bool
+	keyErrs []error
+
+	// keybindings of every view, for the command palette
+	bindings []*viewBindings
+
+	// window cut or copied, to paste in another session
+	clipboard *windowClip
+}
+
+// Options to start the app with.
+type Options struct {
+	// interval between automatic refreshes of the views, 0 disables it
+	RefreshInterval time.Duration
+
+	// when running inside tmux, open tmuxman in a popup over the current client
+	Popup bool
+
+	// save all the sessions to the state file, or restore them, then exit without the ui
+	Save    bool
+	Restore bool
+
+	// save the scrollback of the panes along with the sessions
+	Scrollback bool
 }
 
-func Start() {
+// Default interval between automatic refreshes.
+const DefaultRefreshInterval = 2 * time.Second
+
+func Start(opts Options) {
+	// reopen in a popup if requested, unless this is the popup already
+	inPopup := os.Getenv(popupEnv) != ""
+	if opts.Popup && insideTmux() && !inPopup {
+		if err := openPopup(); err != nil {
+			log.Panic(err)
+		}
+		return
+	}
+
+	// init the log file, the error is shown once the ui is running
+	logErr := initLogger(logPath())
+
+	// load the config, the errors are shown once the ui is running
+	var confErrs []error
+	conf, confErrs = loadConfig(configPath())
+
+	// connect to the tmux server
+	backend, err := newGotmuxBackend()
+	if err != nil {
+		log.Panic(err)
+	}
+
 	// instantiate app
-	app := newApp()
+	app := newApp(backend)
+	app.inPopup = inPopup
+	app.scrollback = opts.Scrollback
+
+	// save or restore the sessions from the command line
+	if opts.Save || opts.Restore {
+		if err := app.runState(opts.Restore); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// init ui and build widget tree
 	app.initUI()
+	startErrs := make([]error, 0)
+	if logErr != nil {
+		startErrs = append(startErrs, logErr)
+	}
+	if errs := append(confErrs, app.keyErrors()...); len(errs) > 0 {
+		startErrs = append(startErrs, fmt.Errorf("%s:\n%w", configPath(), errors.Join(errs...)))
+	}
+	if len(startErrs) > 0 {
+		app.ui.error(errors.Join(startErrs...))
+	}
+
+	// keep the views in sync with tmux
+	app.listen(opts.RefreshInterval)
+	app.autoRefresh(opts.RefreshInterval)
 
 	// run main loop
 	if err := app.ui.Run(); err != nil {
@@ -30,9 +133,11 @@ func Start() {
 	}
 }
 
-func newApp() *App {
+func newApp(backend Backend) *App {
 	// instantiate app, state and tmux api
 	app := &App{}
+	app.backend = backend
+	app.actions = make(map[string]bool)
 	return app
 }
 
@@ -42,23 +147,31 @@ func (a *App) initUI() {
 
 	// init the root views
 	a.initPreview()
+	a.initLive()
 	a.initPanel()
 	a.initTree()
+	a.rejectCommands()
 
 	// build the tabs that can toggle between table and tree view
 	tabs := tview.NewPages()
 	pages := []string{"tree", "panel"}
 	tabs.AddPage(pages[0], a.tree, true, false)
 	tabs.AddPage(pages[1], a.panel, true, false)
-	tabs.ShowPage(pages[0])
+	tabs.ShowPage(conf.DefaultView)
 	tabs.SetBackgroundColor(tcell.ColorNone)
 	setupTabs(tabs, pages)
+	a.tabs = tabs
+
+	// the preview follows the tree until the panel is shown
+	if conf.DefaultView == "panel" {
+		a.panel.refresh(a)
+	}
 
 	// build and set rootFlex view
 	rootFlex := tview.NewFlex()
 	rootFlex.SetTitle("Root")
-	rootFlex.AddItem(tabs, 0, 1, true)
-	rootFlex.AddItem(a.preview, 0, 2, false)
+	rootFlex.AddItem(tabs, 0, 100-conf.PreviewRatio, true)
+	rootFlex.AddItem(a.preview, 0, conf.PreviewRatio, false)
 
 	// build page view as root to enable modals and other widgets
 	root := tview.NewPages()
@@ -69,3 +182,70 @@ func (a *App) initUI() {
 	a.ui.root = root
 	a.ui.SetRoot(root, true)
 }
+
+// Number of ticks between the full refreshes while control mode is active,
+// since tmux does not notify every change (i.e. a pane killed in another session).
+const controlRefreshTicks = 5
+
+// Periodically refreshes the views so that they follow changes made outside of tmuxman.
+func (a *App) autoRefresh(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		for tick := 1; ; tick++ {
+			<-ticker.C
+
+			// skip the tick if the previous refresh did not run yet (i.e. the ui is suspended)
+			if !a.refreshing.CompareAndSwap(false, true) {
+				continue
+			}
+
+			full := tick%controlRefreshTicks == 0
+			a.ui.queue(func() {
+				a.ui.QueueUpdateDraw(func() {
+					// the next tick can refresh once this one ran in the event loop
+					defer a.refreshing.Store(false)
+					a.poll(full)
+				})
+			})
+		}
+	}()
+}
+
+// Refreshes the views on a tick of autoRefresh.
+// Only the preview is polled in control mode, unless full is set.
+func (a *App) poll(full bool) {
+	if a.controlActive.Load() && !full {
+		// most changes are notified in control mode, but not the output of panes
+		// outside of the attached session so the preview is still polled
+		a.preview.refresh()
+		return
+	}
+	a.refresh()
+}
+
+// Syncs the visible view with tmux and re-captures the preview.
+// Errors are only logged since this is not triggered by the user.
+func (a *App) refresh() {
+	var window *gotmux.Window
+	var pane *gotmux.Pane
+	var err error
+	switch page, _ := a.tabs.GetFrontPage(); page {
+	case "tree":
+		if err = a.tree.sync(); err == nil {
+			window, pane, err = a.tree.previewed(a.tree.GetCurrentNode())
+		}
+	case "panel":
+		pane, err = a.panel.sync()
+	}
+
+	if err != nil {
+		logError(err)
+		return
+	}
+
+	a.preview.show(window, pane)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Builds the views of an app over the backend, without running the event loop.
+// An error reported to the user fails the test, unless expectErrors is called.
+func newTestApp(t *testing.T, backend Backend) *App {
+	t.Helper()
+	conf = defaultConfig()
+	a := newApp(backend)
+	a.initUI()
+	a.ui.report = func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	return a
+}
+
+// Errors reported to the user, collected in place of failing the test.
+type reportedErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Collects the errors reported by the app from now on.
+func expectErrors(a *App) *reportedErrors {
+	r := &reportedErrors{}
+	a.ui.report = func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.errs = append(r.errs, err)
+	}
+	return r
+}
+
+// Gets the errors reported so far.
+func (r *reportedErrors) get() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.errs)
+}
+
+// Creates sessions in the fake backend, failing the test on error.
+func newTestSessions(t *testing.T, f *fakeBackend, names ...string) []*gotmux.Session {
+	t.Helper()
+	sessions := make([]*gotmux.Session, 0, len(names))
+	for _, name := range names {
+		s, err := f.NewSession(&gotmux.SessionOptions{Name: name}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// Delivers a key to the app as the event loop does: the app capture first, then the focused view.
+func press(a *App, key tcell.Key, r rune) {
+	event := tcell.NewEventKey(key, r, tcell.ModNone)
+	if capture := a.ui.GetInputCapture(); capture != nil {
+		if event = capture(event); event == nil {
+			return
+		}
+	}
+	a.ui.root.InputHandler()(event, func(p tview.Primitive) {
+		a.ui.SetFocus(p)
+	})
+}
+
+// Presses the keys of the runes of the text, one by one.
+func typeText(a *App, text string) {
+	for _, r := range text {
+		press(a, tcell.KeyRune, r)
+	}
+}
+
+// Fails the test if a modal is still open. Errors are not shown in modals by the tests, see newTestApp.
+func assertNoModal(t *testing.T, a *App) {
+	t.Helper()
+	if a.ui.root.HasPage(modalName) {
+		t.Fatal("unexpected modal open")
+	}
+}
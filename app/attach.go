@@ -0,0 +1,87 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Environment variable set when tmuxman runs in its own popup.
+const popupEnv = "TMUXMAN_POPUP"
+
+// Checks if tmuxman runs inside a tmux client.
+func insideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// Attaches to the session.
+// Inside tmux, the current client is switched to the session instead of nesting a client.
+// tmuxman then keeps running (i.e. in a side pane), unless it runs in its popup which is closed.
+func (a *App) attach(session *gotmux.Session) error {
+	if session == nil {
+		return nil
+	}
+
+	if insideTmux() {
+		if err := a.backend.SwitchClient(session); err != nil {
+			return err
+		}
+
+		if a.inPopup {
+			a.ui.Stop()
+		}
+		return nil
+	}
+
+	var err error
+	a.ui.Suspend(func() {
+		err = a.backend.AttachSession(session)
+	})
+	return err
+}
+
+// Opens tmuxman in a popup over the current tmux client, with the same arguments.
+// The popup is closed when tmuxman exits.
+func openPopup() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// the popup runs a shell command, quote every argument
+	command := []string{"env", popupEnv + "=1", shellQuote(exe)}
+	for _, arg := range os.Args[1:] {
+		command = append(command, shellQuote(arg))
+	}
+
+	cmd := exec.Command("tmux", "display-popup", "-E", "-w", "80%", "-h", "80%", strings.Join(command, " "))
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Quotes a string to be passed as a single shell argument.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Selects the window and pane in the session, then attaches to the session
+// so that the client lands on them. The pane can be nil to keep the active pane.
+func (a *App) attachPane(session *gotmux.Session, window *gotmux.Window, pane *gotmux.Pane) error {
+	if session == nil || window == nil {
+		return nil
+	}
+
+	if err := a.backend.SelectWindow(session, window); err != nil {
+		return err
+	}
+
+	if pane != nil {
+		if err := a.backend.SelectPane(pane); err != nil {
+			return err
+		}
+	}
+
+	return a.attach(session)
+}
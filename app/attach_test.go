@@ -0,0 +1,113 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestAttachInsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	// the client is switched without suspending the ui, which does not run here
+	if err := a.attach(sessions[1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.attached) != 1 || f.attached[0] != "beta" {
+		t.Fatalf("attached = %v, want [beta]", f.attached)
+	}
+}
+
+func TestAttachMissingSession(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	if err := f.KillSession(sessions[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.attach(sessions[0]); err == nil {
+		t.Fatal("expected switching to a killed session to fail")
+	}
+	if len(f.attached) != 0 {
+		t.Fatalf("attached = %v, want none", f.attached)
+	}
+}
+
+func TestAttachPaneFromTree(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	w, err := f.NewWindow(sessions[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panes, err := f.ListPanes(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := f.SplitPane(panes[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SelectPane(panes[0]); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, w.Id, split.Id))
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	windows, err := f.ListWindows(sessions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windows[0].Active || !windows[1].Active {
+		t.Fatalf("window %s not selected", w.Id)
+	}
+	if panes, err = f.ListPanes(w); err != nil {
+		t.Fatal(err)
+	}
+	if panes[0].Active || !panes[1].Active {
+		t.Fatalf("pane %s not selected", split.Id)
+	}
+	if len(f.attached) != 1 || f.attached[0] != "alpha" {
+		t.Fatalf("attached = %v, want [alpha]", f.attached)
+	}
+}
+
+func TestAttachPaneMissingWindow(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	// the window of beta is not in alpha, the client stays where it is
+	windows, err := f.ListWindows(sessions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.attachPane(sessions[0], windows[0], nil); err == nil {
+		t.Fatal("expected selecting a window of another session to fail")
+	}
+	if len(f.attached) != 0 {
+		t.Fatalf("attached = %v, want none", f.attached)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"tmuxman":      "'tmuxman'",
+		"it's":         `'it'\''s'`,
+		"--config=a b": "'--config=a b'",
+	} {
+		if got := shellQuote(in); got != want {
+			t.Fatalf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
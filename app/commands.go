@@ -0,0 +1,234 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Custom command of the config, run against the item selected in the views.
+//
+// Example:
+//
+//	[[commands]]
+//	name = "lazygit"
+//	key = "ctrl+g"
+//	description = "Open lazygit in a new window"
+//	tmux = "new-window -c {pane_path} lazygit"
+//
+//	[[commands]]
+//	name = "clear-history"
+//	key = "C"
+//	description = "Clear the history of the pane"
+//	tmux = "clear-history -t {pane_id}"
+//	confirm = true
+type command struct {
+	// name of the action, shown in the cheatsheet
+	Name string `toml:"name"`
+
+	// keys running the command, like the keys of the config
+	Key string `toml:"key"`
+
+	Description string `toml:"description"`
+
+	// shell command to run, or tmux command run with the tmux binary, with placeholders
+	// replaced by the selected item: {session}, {session_id}, {window}, {window_id},
+	// {window_index}, {pane_id} and {pane_path}
+	Shell string `toml:"shell"`
+	Tmux  string `toml:"tmux"`
+
+	// ask for a confirmation before running the command
+	Confirm bool `toml:"confirm"`
+
+	// parsed key
+	key *Key
+}
+
+// Placeholders of the command templates, i.e. {pane_id}.
+var placeholderRegexp = regexp.MustCompile(`\{(\w+)\}`)
+
+// Names of the placeholders, by the item they need.
+var (
+	sessionPlaceholders = []string{"session", "session_id"}
+	windowPlaceholders  = []string{"window", "window_id", "window_index"}
+	panePlaceholders    = []string{"pane_id", "pane_path"}
+)
+
+// Checks the command and parses its key.
+func (c *command) validate() error {
+	if c.Name == "" {
+		return errors.New("commands need a name")
+	}
+	if (c.Shell == "") == (c.Tmux == "") {
+		return fmt.Errorf("command %s: needs either a shell or a tmux command", c.Name)
+	}
+
+	for _, m := range placeholderRegexp.FindAllStringSubmatch(c.template(), -1) {
+		known := slices.Concat(sessionPlaceholders, windowPlaceholders, panePlaceholders)
+		if !slices.Contains(known, m[1]) {
+			return fmt.Errorf("command %s: unknown placeholder %s", c.Name, m[0])
+		}
+	}
+
+	key, err := parseKeys(c.Key)
+	if err != nil {
+		return fmt.Errorf("command %s: %w", c.Name, err)
+	}
+	c.key = key
+	return nil
+}
+
+// Gets the template of the shell command to run.
+func (c *command) template() string {
+	if c.Tmux != "" {
+		return "tmux " + c.Tmux
+	}
+	return c.Shell
+}
+
+// Replaces the placeholders of the command with the quoted values of the item.
+// The active window and pane are used when the item has none.
+func (a *App) expandCommand(c *command, item *target) (string, error) {
+	tmpl := c.template()
+	uses := func(names []string) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			return strings.Contains(tmpl, "{"+name+"}")
+		})
+	}
+
+	// find the missing items only if they are used
+	var err error
+	window, pane := item.window, item.pane
+	if window == nil && (uses(windowPlaceholders) || uses(panePlaceholders)) {
+		if window, err = a.activeWindow(item.session); err != nil {
+			return "", err
+		}
+	}
+	if pane == nil && uses(panePlaceholders) {
+		if pane, err = a.activePane(window); err != nil {
+			return "", err
+		}
+		if pane == nil {
+			return "", errors.New("no pane in window: " + window.Id)
+		}
+	}
+
+	values := map[string]string{
+		"session":    item.session.Name,
+		"session_id": item.session.Id,
+	}
+	if window != nil {
+		values["window"] = window.Name
+		values["window_id"] = window.Id
+		values["window_index"] = fmt.Sprint(window.Index)
+	}
+	if pane != nil {
+		values["pane_id"] = pane.Id
+		values["pane_path"] = pane.CurrentPath
+	}
+
+	return placeholderRegexp.ReplaceAllStringFunc(tmpl, func(s string) string {
+		return shellQuote(values[s[1:len(s)-1]])
+	}), nil
+}
+
+// Gets the active window of the session.
+func (a *App) activeWindow(session *gotmux.Session) (*gotmux.Window, error) {
+	windows, err := a.backend.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		if window.Active {
+			return window, nil
+		}
+	}
+	return a.firstWindow(session)
+}
+
+// Keybindings of the custom commands of the config, shared by the views.
+func (a *App) commandKeybindings(selected func() *target) []*Keybinding {
+	kh := make([]*Keybinding, 0, len(conf.Commands))
+	for _, c := range conf.Commands {
+		kh = append(kh, &Keybinding{
+			key:         c.key,
+			action:      c.Name,
+			description: c.Description,
+			command:     c,
+			handler: func() {
+				item := selected()
+				if item == nil || item.session == nil {
+					return
+				}
+
+				if !c.Confirm {
+					a.runCommand(c, item)
+					return
+				}
+				a.ui.confirm("Run "+c.Name+" ?", func(b bool) {
+					if b {
+						a.runCommand(c, item)
+					}
+				})
+			},
+		})
+	}
+	return kh
+}
+
+// Runs the command against the item in the background, then shows its output and refreshes the views.
+func (a *App) runCommand(c *command, item *target) {
+	line, err := a.expandCommand(c, item)
+	if err != nil {
+		a.ui.error(err)
+		return
+	}
+
+	// the command may run for long, it must not hold up the refreshes
+	go func() {
+		out, err := exec.Command("sh", "-c", line).CombinedOutput()
+		a.ui.QueueUpdateDraw(func() {
+			msg := strings.TrimSpace(string(out))
+			if err != nil {
+				logError(err)
+				a.ui.openError(fmt.Errorf("%s: %w\n%s", c.Name, err, msg))
+			} else if msg != "" {
+				a.ui.message(c.Name, msg)
+			}
+			a.refresh()
+		})
+	}()
+}
+
+// Disables the custom commands named after a built-in action, since the keys of the config
+// would remap both. The built-in actions are only all known once the views are built.
+func (a *App) rejectCommands() {
+	builtins := make(map[string]bool)
+	for _, v := range a.bindings {
+		for _, binding := range v.keys {
+			if binding.command == nil && binding.action != "" {
+				builtins[binding.action] = true
+			}
+		}
+	}
+
+	for _, v := range a.bindings {
+		for _, binding := range v.keys {
+			if binding.command != nil && builtins[binding.action] {
+				binding.handler = nil
+			}
+		}
+	}
+
+	for _, c := range conf.Commands {
+		if builtins[c.Name] {
+			a.keyErrs = append(a.keyErrs, fmt.Errorf("command %s: name of a built-in action", c.Name))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Builds the views of an app with the custom commands in the config.
+func newCommandsApp(t *testing.T, f *fakeBackend, commands ...*command) *App {
+	t.Helper()
+	conf = defaultConfig()
+	for _, c := range commands {
+		if err := c.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conf.Commands = commands
+
+	a := newApp(f)
+	a.initUI()
+	return a
+}
+
+func TestCommandValidate(t *testing.T) {
+	for _, c := range []*command{
+		{Key: "K", Shell: "true"},
+		{Name: "both", Key: "K", Shell: "true", Tmux: "kill-server"},
+		{Name: "none", Key: "K"},
+		{Name: "unknown", Key: "K", Shell: "echo {client}"},
+		{Name: "badkey", Key: "hyper+k", Shell: "true"},
+	} {
+		if err := c.validate(); err == nil {
+			t.Fatalf("command %+v should be invalid", c)
+		}
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "my app")
+	a := newCommandsApp(t, f)
+
+	c := &command{Name: "open", Key: "O", Tmux: "new-window -t {session_id} -c {pane_path} -n {window}"}
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the active window and pane are used when only the session is selected
+	line, err := a.expandCommand(c, &target{session: sessions[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "tmux new-window -t '$0' -c '' -n 'bash'"; line != want {
+		t.Fatalf("line = %s, want %s", line, want)
+	}
+}
+
+func TestCommandNamedAfterBuiltin(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newCommandsApp(t, f,
+		&command{Name: "kill", Key: "K", Shell: "true"},
+		&command{Name: "lint", Key: "J", Shell: "true"},
+	)
+
+	errs := a.keyErrors()
+	if len(errs) != 1 || errs[0].Error() != "command kill: name of a built-in action" {
+		t.Fatalf("errors = %v, want kill rejected", errs)
+	}
+
+	for _, v := range a.bindings {
+		for _, binding := range v.keys {
+			if binding.command == nil {
+				continue
+			}
+			if disabled := binding.handler == nil; disabled != (binding.action == "kill") {
+				t.Fatalf("command %s in %s: disabled = %v", binding.action, v.name, disabled)
+			}
+		}
+	}
+}
+
+func TestRunCommandInBackground(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	c := &command{Name: "slow", Key: "S", Shell: "sleep 2"}
+	a := newCommandsApp(t, f, c)
+
+	a.runCommand(c, &target{session: sessions[0]})
+
+	// the refreshes are not held up by the command
+	done := make(chan struct{})
+	a.ui.queue(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the refresher is blocked by the command")
+	}
+}
+
+func TestCommandFromTree(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	c := &command{Name: "build", Key: "ctrl+g", Shell: "true", Confirm: true}
+	a := newCommandsApp(t, f, c)
+
+	// the command asks for a confirmation, cancelled here
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "", ""))
+	press(a, tcell.KeyCtrlG, 0)
+	if !a.ui.root.HasPage(modalName) {
+		t.Fatal("confirmation not opened")
+	}
+	press(a, tcell.KeyEscape, 0)
+	assertNoModal(t, a)
+}
+
+func TestHelpHidesRejectedCommands(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newCommandsApp(t, f,
+		&command{Name: "kill", Key: "K", Shell: "true"},
+		&command{Name: "lint", Key: "J", Shell: "true"},
+	)
+
+	press(a, tcell.KeyRune, '?')
+	help, ok := a.ui.GetFocus().(*Table[Keybinding])
+	if !ok {
+		t.Fatal("cheatsheet not focused")
+	}
+
+	commands := make([]string, 0)
+	for _, binding := range help.values {
+		if binding.command != nil {
+			commands = append(commands, binding.action)
+		}
+	}
+	if strings.Join(commands, " ") != "lint" {
+		t.Fatalf("commands listed = %v, want [lint]", commands)
+	}
+}
@@ -0,0 +1,315 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Config of tmuxman, loaded from the config.toml file of the config directory.
+//
+// Example:
+//
+//	theme = "light"
+//	time_format = "2006-01-02 15:04"
+//	title_width = 80
+//	column_width = 40
+//	default_view = "panel"
+//	preview_ratio = 50
+//	history_lines = 5000
+//
+//	[colors]
+//	border = "#ffaf00"
+//	selection = "teal"
+//
+//	[keys]
+//	kill = "K K"
+//	find = "ctrl+f"
+type config struct {
+	// base theme: default, light or mono
+	Theme string `toml:"theme"`
+
+	// colors overriding the ones of the theme, by name or as #rrggbb
+	Colors map[string]string `toml:"colors"`
+
+	// go layout of the times, i.e. "02 Jan 15:04"
+	TimeFormat string `toml:"time_format"`
+
+	// width the titles of the tree nodes are truncated to
+	TitleWidth int `toml:"title_width"`
+
+	// width the names and paths of the tables are truncated to
+	ColumnWidth int `toml:"column_width"`
+
+	// view shown at startup, tree or panel
+	DefaultView string `toml:"default_view"`
+
+	// share of the width taken by the preview, in percent
+	PreviewRatio int `toml:"preview_ratio"`
+
+	// lines of scrollback captured when reading the history of a pane, 0 for all of it
+	HistoryLines int `toml:"history_lines"`
+
+	// keys of the actions, replacing their default keys, i.e. kill = "K K"
+	Keys map[string]string `toml:"keys"`
+
+	// custom commands bound to keys
+	Commands []*command `toml:"commands"`
+
+	// colors of the theme, with the overrides applied
+	theme *theme
+
+	// parsed keys of the actions
+	keys map[string]*Key
+}
+
+// Colors of the views and modals.
+type theme struct {
+	// borders of the focused view and of the modals
+	border tcell.Color
+
+	// titles of the focused view, the modals and the form labels
+	title tcell.Color
+
+	// title of the tree, sessions and windows in the tree
+	accent tcell.Color
+
+	// text, borders and titles of the unfocused views
+	text tcell.Color
+
+	// panes in the tree
+	muted tcell.Color
+
+	// selected row of the focused table and finder
+	selection     tcell.Color
+	selectionText tcell.Color
+
+	// column titles of the tables
+	header tcell.Color
+
+	// background of the input fields and buttons
+	field tcell.Color
+
+	// marked items
+	mark tcell.Color
+
+	// border of the error modal
+	error tcell.Color
+}
+
+// Built-in themes, by name.
+var themes = map[string]theme{
+	"default": {
+		border:        tcell.ColorLightYellow,
+		title:         tcell.ColorLightSteelBlue,
+		accent:        tcell.ColorBlue,
+		text:          tcell.ColorWhite,
+		muted:         tcell.ColorLightGrey,
+		selection:     tcell.ColorLightCyan,
+		selectionText: tcell.ColorBlack,
+		header:        tcell.ColorWheat,
+		field:         tcell.ColorDimGray,
+		mark:          tcell.ColorGold,
+		error:         tcell.ColorRed,
+	},
+	"light": {
+		border:        tcell.ColorDarkOrange,
+		title:         tcell.ColorNavy,
+		accent:        tcell.ColorBlue,
+		text:          tcell.ColorBlack,
+		muted:         tcell.ColorDimGray,
+		selection:     tcell.ColorNavy,
+		selectionText: tcell.ColorWhite,
+		header:        tcell.ColorSaddleBrown,
+		field:         tcell.ColorLightGray,
+		mark:          tcell.ColorDarkMagenta,
+		error:         tcell.ColorRed,
+	},
+	"mono": {
+		border:        tcell.ColorWhite,
+		title:         tcell.ColorWhite,
+		accent:        tcell.ColorWhite,
+		text:          tcell.ColorWhite,
+		muted:         tcell.ColorGray,
+		selection:     tcell.ColorWhite,
+		selectionText: tcell.ColorBlack,
+		header:        tcell.ColorWhite,
+		field:         tcell.ColorDimGray,
+		mark:          tcell.ColorWhite,
+		error:         tcell.ColorWhite,
+	},
+}
+
+// Config in use, the default one until the config file is loaded.
+var conf = defaultConfig()
+
+// Path of the config file.
+func configPath() string {
+	return filepath.Join(configDir(), "config.toml")
+}
+
+// Creates the config used without a config file.
+func defaultConfig() *config {
+	t := themes["default"]
+	return &config{
+		Theme:        "default",
+		TimeFormat:   "02 Jan 15:04",
+		TitleWidth:   60,
+		ColumnWidth:  30,
+		DefaultView:  "tree",
+		PreviewRatio: 67,
+		HistoryLines: 2000,
+		theme:        &t,
+	}
+}
+
+// Loads the config file, the default config is returned if it does not exist.
+// Invalid values are replaced by their default and reported in the returned errors.
+func loadConfig(path string) (*config, []error) {
+	c := defaultConfig()
+	md, err := toml.DecodeFile(path, c)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return defaultConfig(), []error{err}
+	}
+
+	errs := make([]error, 0)
+	for _, key := range md.Undecoded() {
+		errs = append(errs, fmt.Errorf("unknown key: %s", key))
+	}
+
+	return c, append(errs, c.validate()...)
+}
+
+// Checks the values of the config, resetting the invalid ones to their default,
+// and resolves the colors of the theme.
+func (c *config) validate() []error {
+	d := defaultConfig()
+	errs := make([]error, 0)
+	invalid := func(key string, value any) {
+		errs = append(errs, fmt.Errorf("invalid %s: %v", key, value))
+	}
+
+	// a layout without any element of the reference time prints the same text for every time
+	if t := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC); t.Format(c.TimeFormat) == c.TimeFormat {
+		invalid("time_format", c.TimeFormat)
+		c.TimeFormat = d.TimeFormat
+	}
+
+	if c.TitleWidth < 10 {
+		invalid("title_width", c.TitleWidth)
+		c.TitleWidth = d.TitleWidth
+	}
+
+	if c.ColumnWidth < 10 {
+		invalid("column_width", c.ColumnWidth)
+		c.ColumnWidth = d.ColumnWidth
+	}
+
+	if c.DefaultView != "tree" && c.DefaultView != "panel" {
+		invalid("default_view", c.DefaultView)
+		c.DefaultView = d.DefaultView
+	}
+
+	if c.PreviewRatio < 10 || c.PreviewRatio > 90 {
+		invalid("preview_ratio", c.PreviewRatio)
+		c.PreviewRatio = d.PreviewRatio
+	}
+
+	if c.HistoryLines < 0 {
+		invalid("history_lines", c.HistoryLines)
+		c.HistoryLines = d.HistoryLines
+	}
+
+	t, ok := themes[c.Theme]
+	if !ok {
+		invalid("theme", c.Theme)
+		c.Theme = d.Theme
+		t = themes[c.Theme]
+	}
+
+	// sorted to report the errors in a stable order
+	for _, name := range slices.Sorted(maps.Keys(c.Colors)) {
+		color, ok := parseColor(c.Colors[name])
+		if !ok {
+			invalid("color "+name, c.Colors[name])
+			continue
+		}
+		if !t.set(name, color) {
+			errs = append(errs, fmt.Errorf("unknown color: %s", name))
+		}
+	}
+	c.theme = &t
+
+	// unknown actions are only found once the views are built
+	c.keys = make(map[string]*Key)
+	for _, action := range slices.Sorted(maps.Keys(c.Keys)) {
+		key, err := parseKeys(c.Keys[action])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid keys for %s: %w", action, err))
+			continue
+		}
+		c.keys[action] = key
+	}
+
+	// invalid commands are dropped
+	commands := make([]*command, 0, len(c.Commands))
+	for _, cmd := range c.Commands {
+		err := cmd.validate()
+		if err == nil && slices.ContainsFunc(commands, func(other *command) bool { return other.Name == cmd.Name }) {
+			err = errors.New("duplicate command: " + cmd.Name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+	c.Commands = commands
+
+	return errs
+}
+
+// Sets the color with the name, returns false if there is no such color.
+func (t *theme) set(name string, color tcell.Color) bool {
+	colors := map[string]*tcell.Color{
+		"border":         &t.border,
+		"title":          &t.title,
+		"accent":         &t.accent,
+		"text":           &t.text,
+		"muted":          &t.muted,
+		"selection":      &t.selection,
+		"selection_text": &t.selectionText,
+		"header":         &t.header,
+		"field":          &t.field,
+		"mark":           &t.mark,
+		"error":          &t.error,
+	}
+
+	c, ok := colors[name]
+	if ok {
+		*c = color
+	}
+	return ok
+}
+
+// Parses a color by name or as #rrggbb, "default" being the color of the terminal.
+func parseColor(s string) (tcell.Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "default" {
+		return tcell.ColorDefault, true
+	}
+
+	c := tcell.GetColor(s)
+	return c, c != tcell.ColorDefault
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Writes the config file in a temporary directory and loads it.
+func loadTestConfig(t *testing.T, content string) (*config, []error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return loadConfig(path)
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	c, errs := loadConfig(filepath.Join(t.TempDir(), "config.toml"))
+	if len(errs) > 0 {
+		t.Fatalf("errors = %v, want none", errs)
+	}
+	if d := defaultConfig(); c.Theme != d.Theme || c.TimeFormat != d.TimeFormat || c.PreviewRatio != d.PreviewRatio {
+		t.Fatalf("config = %+v, want the default", c)
+	}
+}
+
+func TestLoadConfigValid(t *testing.T) {
+	c, errs := loadTestConfig(t, `
+theme = "light"
+time_format = "2006-01-02"
+preview_ratio = 50
+
+[colors]
+border = "#ffaf00"
+mark = "default"
+
+[keys]
+kill = "K K"
+`)
+	if len(errs) > 0 {
+		t.Fatalf("errors = %v, want none", errs)
+	}
+	if c.Theme != "light" || c.TimeFormat != "2006-01-02" || c.PreviewRatio != 50 {
+		t.Fatalf("config = %+v", c)
+	}
+	if c.theme.border != tcell.NewHexColor(0xffaf00) || c.theme.mark != tcell.ColorDefault {
+		t.Fatalf("colors = %v %v, want the overrides", c.theme.border, c.theme.mark)
+	}
+	if c.theme.title != themes["light"].title {
+		t.Fatalf("title color = %v, want the one of the light theme", c.theme.title)
+	}
+	if c.keys["kill"] == nil {
+		t.Fatal("keys of kill not parsed")
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	d := defaultConfig()
+	cases := []struct {
+		name    string
+		content string
+		err     string
+		check   func(c *config) bool
+	}{
+		{
+			"time format", `time_format = "dd/mm"`, "invalid time_format",
+			func(c *config) bool { return c.TimeFormat == d.TimeFormat },
+		},
+		{
+			"preview ratio", `preview_ratio = 95`, "invalid preview_ratio",
+			func(c *config) bool { return c.PreviewRatio == d.PreviewRatio },
+		},
+		{
+			"theme", `theme = "solarized"`, "invalid theme",
+			func(c *config) bool { return c.Theme == d.Theme && c.theme.border == themes["default"].border },
+		},
+		{
+			"color value", "[colors]\nborder = \"not-a-color\"", "invalid color border",
+			func(c *config) bool { return c.theme.border == themes["default"].border },
+		},
+		{
+			"color name", "[colors]\nshadow = \"red\"", "unknown color: shadow",
+			func(c *config) bool { return true },
+		},
+		{
+			"unknown key", `refresh = 5`, "unknown key: refresh",
+			func(c *config) bool { return true },
+		},
+		{
+			"keys", "[keys]\nkill = \"ctrl+\"", "invalid keys for kill",
+			func(c *config) bool { return c.keys["kill"] == nil },
+		},
+		{
+			"syntax", `theme = `, "",
+			func(c *config) bool { return c.Theme == d.Theme && c.theme != nil },
+		},
+	}
+
+	for _, tc := range cases {
+		// the other values are kept
+		c, errs := loadTestConfig(t, "title_width = 40\n"+tc.content)
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.err) {
+			t.Fatalf("%s: errors = %v, want %q", tc.name, errs, tc.err)
+		}
+		if !tc.check(c) {
+			t.Fatalf("%s: invalid value not reset to its default: %+v", tc.name, c)
+		}
+		if tc.err != "" && c.TitleWidth != 40 {
+			t.Fatalf("%s: title_width = %d, want 40", tc.name, c.TitleWidth)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Notification sent by tmux to control mode clients, i.e. `%window-renamed @1 name`.
+//
+// Reference: https://github.com/tmux/tmux/wiki/Control-Mode#notifications
+type notification struct {
+	name string
+	args []string
+}
+
+// Parses a notification line.
+// The last argument holds the rest of the line since names and output can contain spaces.
+func parseNotification(line string) notification {
+	parts := strings.SplitN(line, " ", 3)
+	return notification{
+		name: parts[0],
+		args: parts[1:],
+	}
+}
+
+// Gets an argument of the notification, empty if missing.
+func (n notification) arg(idx int) string {
+	if idx >= len(n.args) {
+		return ""
+	}
+
+	return n.args[idx]
+}
+
+// Runs a tmux client in control mode and passes every notification to the handler,
+// until the client exits. The started func is called once the client is attached.
+//
+// The client attaches to the most recent session, ignoring its size and in read-only mode,
+// so it fails when there is no session to attach to. Being attached, it is counted in the
+// clients of the session, the backend leaves it attached when detaching the session.
+func runControlMode(started func(), handle func(notification)) error {
+	cmd := exec.Command("tmux", "-C", "attach-session", "-f", "ignore-size,read-only")
+
+	// the client exits when its input is closed, keep it open while running
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	defer stdin.Close()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// read the lines, skipping the output blocks of commands
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	inBlock := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "%begin"):
+			inBlock = true
+		case strings.HasPrefix(line, "%end"), strings.HasPrefix(line, "%error"):
+			// the first block is the reply to the attach, the client is ready
+			if started != nil {
+				started()
+				started = nil
+			}
+			inBlock = false
+		case inBlock:
+		case strings.HasPrefix(line, "%"):
+			handle(parseNotification(line))
+		}
+	}
+
+	return cmd.Wait()
+}
+
+// Listens to tmux notifications in control mode to patch the views as changes happen.
+// While control mode is unavailable (no server or no session), the views are polled by
+// autoRefresh and the control client is retried at every interval.
+func (a *App) listen(retry time.Duration) {
+	if retry <= 0 {
+		retry = DefaultRefreshInterval
+	}
+
+	go func() {
+		for {
+			err := runControlMode(func() {
+				a.controlActive.Store(true)
+			}, func(n notification) {
+				// the output of every pane is notified, only the previewed ones are re-captured
+				if n.name == "%output" {
+					if a.preview.showing(n.arg(0)) {
+						a.capturePreview()
+					}
+					return
+				}
+
+				a.ui.QueueUpdate(func() {
+					if a.notify(n) {
+						a.ui.ForceDraw()
+					}
+				})
+			})
+
+			// notifications may have been missed, sync everything
+			if a.controlActive.Swap(false) {
+				if err != nil {
+					logError(err)
+				}
+				a.ui.QueueUpdateDraw(a.refresh)
+			}
+
+			time.Sleep(retry)
+		}
+	}()
+}
+
+// Patches the views according to the notification.
+// Only the parts affected by the change are synced, to avoid querying all of tmux.
+// Returns false if the notification changes nothing in the views.
+func (a *App) notify(n notification) bool {
+	var err error
+	switch n.name {
+	case "%sessions-changed":
+		err = a.tree.syncSessions(false)
+	case "%session-renamed":
+		a.tree.renameSession(n.arg(0), n.arg(1))
+	case "%session-window-changed":
+		err = a.tree.syncSessionById(n.arg(0))
+
+	case "%window-add", "%unlinked-window-add":
+		err = a.tree.addWindow(n.arg(0))
+	case "%window-close", "%unlinked-window-close":
+		a.tree.removeWindow(n.arg(0))
+	case "%window-renamed", "%unlinked-window-renamed":
+		a.tree.renameWindow(n.arg(0), n.arg(1))
+	case "%layout-change", "%window-pane-changed":
+		err = a.tree.syncWindowById(n.arg(0))
+
+	default:
+		return false
+	}
+	if err != nil {
+		logError(err)
+	}
+
+	// the panel only shows a single session and window, sync from the affected level down
+	var pane *gotmux.Pane
+	session := a.panel.sessions.getSelected()
+	window := a.panel.windows.getSelected()
+	switch {
+	case n.name == "%sessions-changed" || n.name == "%session-renamed" || session == nil:
+		pane, err = a.panel.sync()
+	case n.name == "%layout-change" || n.name == "%window-pane-changed":
+		pane = a.panel.panes.getSelected()
+		if window != nil && window.Id == n.arg(0) {
+			pane, err = a.panel.syncPanesDown(window)
+		}
+	default:
+		pane, err = a.panel.syncWindowsDown(session)
+	}
+	if err != nil {
+		logError(err)
+		return true
+	}
+
+	// the tree preview is updated by its changed func
+	if page, _ := a.tabs.GetFrontPage(); page == "panel" {
+		a.preview.update(pane)
+	}
+	return true
+}
+
+// Throttle period for capturing the preview on output.
+const captureThrottle = 100 * time.Millisecond
+
+// Re-captures the preview after the throttle period, unless already scheduled.
+func (a *App) capturePreview() {
+	if !a.capturing.CompareAndSwap(false, true) {
+		return
+	}
+
+	time.AfterFunc(captureThrottle, func() {
+		a.ui.QueueUpdateDraw(func() {
+			a.capturing.Store(false)
+			a.preview.refresh()
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+func TestParseNotification(t *testing.T) {
+	n := parseNotification("%window-renamed @1 my window")
+	if n.name != "%window-renamed" || n.arg(0) != "@1" || n.arg(1) != "my window" {
+		t.Fatalf("notification = %+v", n)
+	}
+	if n.arg(2) != "" {
+		t.Fatalf("missing argument = %q, want empty", n.arg(2))
+	}
+}
+
+func TestNotifyWindows(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	w, err := f.NewWindow(sessions[1], &gotmux.NewWindowOptions{WindowName: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.notify(notification{name: "%window-add", args: []string{w.Id}}) {
+		t.Fatal("window add changed nothing")
+	}
+	if a.tree.reveal(sessions[1].Id, w.Id, "") == nil {
+		t.Fatalf("window %s not added to the tree", w.Id)
+	}
+	if got := len(a.panel.windows.values); got != 1 {
+		t.Fatalf("panel windows of alpha = %d, want 1", got)
+	}
+
+	if !a.notify(notification{name: "%window-renamed", args: []string{w.Id, "build logs"}}) {
+		t.Fatal("window rename changed nothing")
+	}
+	if name := unwrapNode(a.tree.reveal(sessions[1].Id, w.Id, "")).window().Name; name != "build logs" {
+		t.Fatalf("window name = %q, want %q", name, "build logs")
+	}
+
+	if err := f.KillWindow(w); err != nil {
+		t.Fatal(err)
+	}
+	if !a.notify(notification{name: "%window-close", args: []string{w.Id}}) {
+		t.Fatal("window close changed nothing")
+	}
+	if a.tree.reveal(sessions[1].Id, w.Id, "") != nil {
+		t.Fatalf("window %s still in the tree", w.Id)
+	}
+}
+
+func TestNotifyIgnored(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	for _, name := range []string{"%output", "%client-detached", "%pane-mode-changed"} {
+		if a.notify(notification{name: name, args: []string{"%0"}}) {
+			t.Fatalf("%s changed the views", name)
+		}
+	}
+}
+
+func TestPollInControlMode(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "aa", "bb")
+	pane, err := f.SplitPane(&gotmux.Pane{Id: "%1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+	a.controlActive.Store(true)
+
+	// tmux notifies nothing when a pane of another session than the attached one is killed
+	if err := f.KillPane(pane); err != nil {
+		t.Fatal(err)
+	}
+	a.poll(false)
+	if a.tree.reveal(sessions[1].Id, "@1", pane.Id) == nil {
+		t.Fatal("the tree was synced without a full refresh")
+	}
+	a.poll(true)
+	if a.tree.reveal(sessions[1].Id, "@1", pane.Id) != nil {
+		t.Fatalf("killed pane %s still in the tree", pane.Id)
+	}
+}
+
+func TestPreviewShowing(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+	p := a.preview
+
+	p.show(nil, &gotmux.Pane{Id: "%0"})
+	if !p.showing("%0") || p.showing("%1") {
+		t.Fatal("only the previewed pane should be shown")
+	}
+
+	windows, err := f.ListWindows(sessions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.show(windows[0], nil)
+	if !p.showing("%0") || !p.showing("%1") {
+		t.Fatal("the panes of the previewed window should be shown")
+	}
+
+	p.showError(errors.New("no server running"))
+	if !p.showing(p.pane.Id) || len(*p.shown.Load()) != 1 {
+		t.Fatal("only the active pane should be shown after an error")
+	}
+}
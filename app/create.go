@@ -0,0 +1,120 @@
+package app
+
+import (
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Keybindings creating windows and splitting panes, shared by the views.
+func (a *App) createKeybindings(selected func() *target) []*Keybinding {
+	split := func(direction gotmux.PaneSplitDirection) func() {
+		return func() {
+			if item := selected(); item != nil {
+				if err := a.splitPane(item, direction); err != nil {
+					a.ui.error(err)
+				}
+			}
+		}
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'c',
+				display: "c",
+			},
+			action:      "new-window",
+			description: "Create a new window",
+			handler: func() {
+				if item := selected(); item != nil {
+					a.newWindow(item.session)
+				}
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '%',
+				display: "%",
+			},
+			action:      "split-horizontal",
+			description: "Split pane horizontally",
+			handler:     split(gotmux.PaneSplitDirectionHorizontal),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '"',
+				display: "\"",
+			},
+			action:      "split-vertical",
+			description: "Split pane vertically",
+			handler:     split(gotmux.PaneSplitDirectionVertical),
+		},
+	}
+}
+
+// Prompts for the name and start directory of a new window in the session,
+// then creates it and selects it in the visible view.
+func (a *App) newWindow(session *gotmux.Session) {
+	a.ui.editor("New window name", "", func(name string) {
+		a.ui.editor("Start directory", session.Path, func(dir string) {
+			// do not move the clients attached to the session to the new window
+			window, err := a.backend.NewWindow(session, &gotmux.NewWindowOptions{
+				WindowName:     name,
+				StartDirectory: expandHome(dir),
+				DoNotAttach:    true,
+			})
+			if err != nil {
+				a.ui.error(err)
+				return
+			}
+
+			if err := a.jump(&target{session: session, window: window}); err != nil {
+				a.ui.error(err)
+			}
+		})
+	})
+}
+
+// Splits the pane of the item, or the active pane of its window, in the direction.
+// The new pane starts in the same directory and is selected in the visible view.
+func (a *App) splitPane(item *target, direction gotmux.PaneSplitDirection) error {
+	if item.window == nil {
+		return nil
+	}
+
+	pane := item.pane
+	if pane == nil {
+		var err error
+		if pane, err = a.activePane(item.window); err != nil || pane == nil {
+			return err
+		}
+	}
+
+	newPane, err := a.backend.SplitPane(pane, &gotmux.SplitWindowOptions{
+		SplitDirection: direction,
+		StartDirectory: pane.CurrentPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.jump(&target{session: item.session, window: item.window, pane: newPane})
+}
+
+// Gets the active pane of the window, nil if it has no panes.
+func (a *App) activePane(window *gotmux.Window) (*gotmux.Pane, error) {
+	panes, err := a.backend.ListPanes(window)
+	if err != nil || len(panes) == 0 {
+		return nil, err
+	}
+
+	for _, pane := range panes {
+		if pane.Active {
+			return pane, nil
+		}
+	}
+	return panes[0], nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestNewWindowFromTree(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "", ""))
+	press(a, tcell.KeyRune, 'c')
+	typeText(a, "logs")
+	press(a, tcell.KeyEnter, 0)
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	windows, err := f.ListWindows(sessions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1].Name != "logs" {
+		t.Fatalf("windows = %v, want a second window named logs", windows)
+	}
+	if windows[1].Active {
+		t.Fatal("the new window should not be selected in tmux")
+	}
+	if n := unwrapNode(a.tree.GetCurrentNode()); n.typ != Window || n.id() != windows[1].Id {
+		t.Fatalf("current node = %s, want window %s", n.id(), windows[1].Id)
+	}
+}
+
+func TestSplitPaneFromTree(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	// the active pane is split when a window is selected
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", ""))
+	press(a, tcell.KeyRune, '%')
+	assertNoModal(t, a)
+
+	panes, err := f.ListPanes(&gotmux.Window{Id: "@0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(panes) != 2 || panes[0].Width != 39 || panes[1].Width != 40 {
+		t.Fatalf("panes = %v, want two side by side", panes)
+	}
+	if n := unwrapNode(a.tree.GetCurrentNode()); n.typ != Pane || n.id() != panes[1].Id {
+		t.Fatalf("current node = %s, want pane %s", n.id(), panes[1].Id)
+	}
+
+	press(a, tcell.KeyRune, '"')
+	if panes, err = f.ListPanes(&gotmux.Window{Id: "@0"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(panes) != 3 || panes[1].Height != 11 || panes[2].Height != 12 {
+		t.Fatalf("panes = %v, want the second one split in height", panes)
+	}
+}
+
+func TestSplitPaneFromPanel(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+	showPanel(t, a)
+	a.ui.SetFocus(a.panel.windows)
+
+	press(a, tcell.KeyRune, '"')
+	assertNoModal(t, a)
+
+	if got := len(a.panel.panes.values); got != 2 {
+		t.Fatalf("panel panes = %d, want 2", got)
+	}
+	if pane := a.panel.panes.getSelected(); pane.Id != "%1" {
+		t.Fatalf("selected pane = %s, want %%1", pane.Id)
+	}
+}
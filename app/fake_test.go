@@ -0,0 +1,964 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// In-memory backend holding a scripted tmux state.
+// It mimics the tmux behaviors the views depend on (killing the last pane kills the window,
+// killing the last window kills the session...) so that the views can be driven without a server.
+type fakeBackend struct {
+	mu sync.Mutex
+
+	// sessions in creation order
+	sessions []*gotmux.Session
+
+	// windows by session id and panes by window id
+	windows map[string][]*gotmux.Window
+	panes   map[string][]*gotmux.Pane
+
+	// captured content by pane id
+	content map[string]string
+
+	// environment by session id
+	env map[string]map[string]string
+
+	// keys sent by pane id, in order
+	keys map[string][]string
+
+	// names of the sessions that were attached or switched to, in order
+	attached []string
+
+	// names of the sessions whose clients were detached, in order
+	detached []string
+
+	// if set, every operation fails with this error
+	err error
+
+	// id counters
+	nextSession int
+	nextWindow  int
+	nextPane    int
+}
+
+// Creates an empty fake backend.
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		windows: make(map[string][]*gotmux.Window),
+		panes:   make(map[string][]*gotmux.Pane),
+		content: make(map[string]string),
+		env:     make(map[string]map[string]string),
+		keys:    make(map[string][]string),
+	}
+}
+
+// Sets the content returned when capturing the pane.
+func (f *fakeBackend) setContent(paneId string, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.content[paneId] = content
+}
+
+func (f *fakeBackend) ListSessions() ([]*gotmux.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	out := make([]*gotmux.Session, 0, len(f.sessions))
+	for _, s := range f.sessions {
+		c := *s
+		c.Windows = len(f.windows[s.Id])
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) NewSession(op *gotmux.SessionOptions, env map[string]string) (*gotmux.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	id := f.nextSession
+	f.nextSession++
+
+	name := strconv.Itoa(id)
+	startDir := ""
+	command := ""
+	if op != nil {
+		if op.Name != "" {
+			name = op.Name
+		}
+		startDir = op.StartDirectory
+		command = op.ShellCommand
+	}
+
+	if f.findSession(name) != nil {
+		return nil, fmt.Errorf("duplicate session: %s", name)
+	}
+
+	s := &gotmux.Session{
+		Id:       "$" + strconv.Itoa(id),
+		Name:     name,
+		Path:     startDir,
+		Activity: "0",
+		Created:  "0",
+	}
+	f.sessions = append(f.sessions, s)
+	f.env[s.Id] = env
+	f.addWindow(s, "", startDir, command)
+
+	c := *s
+	return &c, nil
+}
+
+func (f *fakeBackend) RenameSession(session *gotmux.Session, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+	if f.findSession(name) != nil {
+		return fmt.Errorf("duplicate session: %s", name)
+	}
+
+	s.Name = name
+	return nil
+}
+
+func (f *fakeBackend) KillSession(session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	f.removeSession(s)
+	return nil
+}
+
+func (f *fakeBackend) AttachSession(session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.findSession(session.Name) == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	f.attached = append(f.attached, session.Name)
+	return nil
+}
+
+func (f *fakeBackend) DetachSession(session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.findSession(session.Name) == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	f.detached = append(f.detached, session.Name)
+	return nil
+}
+
+func (f *fakeBackend) SwitchClient(session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.findSession(session.Name) == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	f.attached = append(f.attached, session.Name)
+	return nil
+}
+
+func (f *fakeBackend) ListWindows(session *gotmux.Session) ([]*gotmux.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return nil, fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	out := make([]*gotmux.Window, 0)
+	for _, w := range f.windows[s.Id] {
+		c := *w
+		c.Panes = len(f.panes[w.Id])
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) NewWindow(session *gotmux.Session, op *gotmux.NewWindowOptions) (*gotmux.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return nil, fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	name := ""
+	startDir := ""
+	if op != nil {
+		name = op.WindowName
+		startDir = op.StartDirectory
+	}
+
+	w := f.addWindow(s, name, startDir, "")
+	c := *w
+	return &c, nil
+}
+
+func (f *fakeBackend) RenameWindow(window *gotmux.Window, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	links := f.windowLinks(window.Id)
+	if len(links) == 0 {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	for _, w := range links {
+		w.Name = name
+	}
+	return nil
+}
+
+func (f *fakeBackend) KillWindow(window *gotmux.Window) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.findWindow(window.Id) == nil {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	f.removeWindow(window.Id)
+	return nil
+}
+
+func (f *fakeBackend) ListLinkedSessions(window *gotmux.Window) ([]*gotmux.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	out := make([]*gotmux.Session, 0)
+	for _, s := range f.sessions {
+		for _, w := range f.windows[s.Id] {
+			if w.Id == window.Id {
+				c := *s
+				out = append(out, &c)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) GetWindow(id string) (*gotmux.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	w := f.findWindow(id)
+	if w == nil {
+		return nil, nil
+	}
+
+	c := *w
+	c.Panes = len(f.panes[w.Id])
+	return &c, nil
+}
+
+func (f *fakeBackend) SelectWindow(session *gotmux.Session, window *gotmux.Window) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	windows := f.windows[s.Id]
+	if !slices.ContainsFunc(windows, func(w *gotmux.Window) bool { return w.Id == window.Id }) {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	for _, w := range windows {
+		w.Active = w.Id == window.Id
+	}
+	return nil
+}
+
+func (f *fakeBackend) SelectLayout(window *gotmux.Window, layout string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	w := f.findWindow(window.Id)
+	if w == nil {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	// custom layouts are the layout strings of the windows, i.e. "b25d,80x24,0,0,1"
+	layouts := []string{"even-horizontal", "even-vertical", "main-horizontal", "main-vertical", "tiled"}
+	if !slices.Contains(layouts, layout) && !strings.Contains(layout, ",") {
+		return fmt.Errorf("invalid layout: %s", layout)
+	}
+
+	w.Layout = layout
+	return nil
+}
+
+func (f *fakeBackend) MoveWindow(window *gotmux.Window, from *gotmux.Session, to *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	src := f.findSession(from.Name)
+	if src == nil {
+		return fmt.Errorf("can't find session: %s", from.Name)
+	}
+	dst := f.findSession(to.Name)
+	if dst == nil {
+		return fmt.Errorf("can't find session: %s", to.Name)
+	}
+
+	idx := slices.IndexFunc(f.windows[src.Id], func(w *gotmux.Window) bool { return w.Id == window.Id })
+	if idx < 0 {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+	if src == dst {
+		return nil
+	}
+
+	w := f.windows[src.Id][idx]
+	f.unlink(src, idx)
+	f.link(w, dst)
+	return nil
+}
+
+func (f *fakeBackend) LinkWindow(window *gotmux.Window, session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	w := f.findWindow(window.Id)
+	if w == nil {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	f.link(w, s)
+	return nil
+}
+
+func (f *fakeBackend) UnlinkWindow(window *gotmux.Window, session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	idx := slices.IndexFunc(f.windows[s.Id], func(w *gotmux.Window) bool { return w.Id == window.Id })
+	if idx < 0 {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+	if len(f.windowLinks(window.Id)) == 1 {
+		return errors.New("window only linked to one session")
+	}
+
+	f.unlink(s, idx)
+	return nil
+}
+
+func (f *fakeBackend) SwapWindows(session *gotmux.Session, window *gotmux.Window, other *gotmux.Window) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	windows := f.windows[s.Id]
+	i := slices.IndexFunc(windows, func(w *gotmux.Window) bool { return w.Id == window.Id })
+	j := slices.IndexFunc(windows, func(w *gotmux.Window) bool { return w.Id == other.Id })
+	if i < 0 || j < 0 {
+		return fmt.Errorf("can't find windows: %s, %s", window.Id, other.Id)
+	}
+
+	// the windows trade their indexes, the order stays the one of the indexes
+	windows[i].Index, windows[j].Index = windows[j].Index, windows[i].Index
+	windows[i], windows[j] = windows[j], windows[i]
+	return nil
+}
+
+func (f *fakeBackend) RenumberWindows(session *gotmux.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	s := f.findSession(session.Name)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	for idx, w := range f.windows[s.Id] {
+		w.Index = idx
+	}
+	return nil
+}
+
+func (f *fakeBackend) ListPanes(window *gotmux.Window) ([]*gotmux.Pane, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if f.findWindow(window.Id) == nil {
+		return nil, fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	out := make([]*gotmux.Pane, 0)
+	for _, p := range f.panes[window.Id] {
+		c := *p
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) SplitPane(pane *gotmux.Pane, op *gotmux.SplitWindowOptions) (*gotmux.Pane, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return nil, fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	startDir := ""
+	command := ""
+	if op != nil {
+		startDir = op.StartDirectory
+		command = op.ShellCommand
+	}
+
+	// the split pane gives the right or bottom half of its cells to the new one, minus the border
+	idx := slices.IndexFunc(f.panes[windowId], func(p *gotmux.Pane) bool { return p.Id == pane.Id })
+	src := f.panes[windowId][idx]
+	p := f.addPane(windowId, startDir, command)
+	p.Left, p.Top, p.Width, p.Height = src.Left, src.Top, src.Width, src.Height
+	if op != nil && op.SplitDirection == gotmux.PaneSplitDirectionHorizontal {
+		left, _ := strconv.Atoi(src.Left)
+		src.Width = (src.Width - 1) / 2
+		p.Width -= src.Width + 1
+		p.Left = strconv.Itoa(left + src.Width + 1)
+	} else {
+		top, _ := strconv.Atoi(src.Top)
+		src.Height = (src.Height - 1) / 2
+		p.Height -= src.Height + 1
+		p.Top = strconv.Itoa(top + src.Height + 1)
+	}
+
+	c := *p
+	return &c, nil
+}
+
+func (f *fakeBackend) KillPane(pane *gotmux.Pane) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	// killing the last pane kills the window
+	panes := f.panes[windowId]
+	if len(panes) == 1 {
+		f.removeWindow(windowId)
+		return nil
+	}
+
+	for idx, p := range panes {
+		if p.Id == pane.Id {
+			f.panes[windowId] = append(panes[:idx:idx], panes[idx+1:]...)
+			break
+		}
+	}
+	delete(f.content, pane.Id)
+	return nil
+}
+
+func (f *fakeBackend) SelectPane(pane *gotmux.Pane) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	for _, p := range f.panes[windowId] {
+		p.Active = p.Id == pane.Id
+	}
+	return nil
+}
+
+func (f *fakeBackend) SwapPanes(pane *gotmux.Pane, other *gotmux.Pane) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	// the panes may be in different windows
+	find := func(id string) (string, int) {
+		windowId := f.paneWindow(id)
+		return windowId, slices.IndexFunc(f.panes[windowId], func(p *gotmux.Pane) bool { return p.Id == id })
+	}
+	w1, i := find(pane.Id)
+	w2, j := find(other.Id)
+	if w1 == "" || w2 == "" {
+		return fmt.Errorf("can't find panes: %s, %s", pane.Id, other.Id)
+	}
+
+	p1, p2 := f.panes[w1][i], f.panes[w2][j]
+	p1.Index, p2.Index = p2.Index, p1.Index
+	f.panes[w1][i], f.panes[w2][j] = p2, p1
+	return nil
+}
+
+func (f *fakeBackend) ResizePane(pane *gotmux.Pane, direction string, cells int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	// only the size of the pane itself changes, as if its right or bottom border moved
+	idx := slices.IndexFunc(f.panes[windowId], func(p *gotmux.Pane) bool { return p.Id == pane.Id })
+	p := f.panes[windowId][idx]
+	switch direction {
+	case "L":
+		p.Width = max(1, p.Width-cells)
+	case "R":
+		p.Width += cells
+	case "U":
+		p.Height = max(1, p.Height-cells)
+	case "D":
+		p.Height += cells
+	default:
+		return fmt.Errorf("invalid direction: %s", direction)
+	}
+	return nil
+}
+
+func (f *fakeBackend) ZoomPane(pane *gotmux.Pane) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	for _, w := range f.windowLinks(windowId) {
+		w.ZoomedFlag = !w.ZoomedFlag
+	}
+	for _, p := range f.panes[windowId] {
+		p.Active = p.Id == pane.Id
+	}
+	return nil
+}
+
+func (f *fakeBackend) BreakPane(pane *gotmux.Pane, session *gotmux.Session) (*gotmux.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	windowId := f.paneWindow(pane.Id)
+	if windowId == "" {
+		return nil, fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+	s := f.findSession(session.Name)
+	if s == nil {
+		return nil, fmt.Errorf("can't find session: %s", session.Name)
+	}
+
+	// the new window is added first, so that the session survives if the pane was its last one
+	panes := f.panes[windowId]
+	idx := slices.IndexFunc(panes, func(p *gotmux.Pane) bool { return p.Id == pane.Id })
+	p := panes[idx]
+	w := f.addWindow(s, p.CurrentCommand, "", "")
+	p.Index = 0
+	p.Active = true
+	f.panes[w.Id] = []*gotmux.Pane{p}
+
+	// the panes left are renumbered, and the window is killed if it has none left
+	panes = slices.Delete(panes, idx, idx+1)
+	for i, cur := range panes {
+		cur.Index = i
+	}
+	f.panes[windowId] = panes
+	if len(panes) == 0 {
+		f.removeWindow(windowId)
+	} else if !slices.ContainsFunc(panes, func(p *gotmux.Pane) bool { return p.Active }) {
+		panes[0].Active = true
+	}
+
+	c := *w
+	c.Panes = 1
+	return &c, nil
+}
+
+func (f *fakeBackend) RotatePanes(window *gotmux.Window, reverse bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	panes := f.panes[window.Id]
+	if f.findWindow(window.Id) == nil || len(panes) == 0 {
+		return fmt.Errorf("can't find window: %s", window.Id)
+	}
+
+	// every pane takes the place of the next one, or of the previous one in reverse
+	if reverse {
+		panes = append(panes[1:], panes[0])
+	} else {
+		panes = append([]*gotmux.Pane{panes[len(panes)-1]}, panes[:len(panes)-1]...)
+	}
+	for idx, p := range panes {
+		p.Index = idx
+	}
+	f.panes[window.Id] = panes
+	return nil
+}
+
+func (f *fakeBackend) SendKeys(pane *gotmux.Pane, literal bool, keys ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.paneWindow(pane.Id) == "" {
+		return fmt.Errorf("can't find pane: %s", pane.Id)
+	}
+
+	f.keys[pane.Id] = append(f.keys[pane.Id], keys...)
+	return nil
+}
+
+func (f *fakeBackend) CapturePane(pane *gotmux.Pane) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+
+	if f.paneWindow(pane.Id) == "" {
+		return "", errors.New("can't find pane: " + pane.Id)
+	}
+
+	return f.content[pane.Id], nil
+}
+
+func (f *fakeBackend) CaptureHistory(pane *gotmux.Pane, lines int) (string, error) {
+	// the fake has no scrollback, only the content
+	return f.CapturePane(pane)
+}
+
+// Finds a session by name. Must be called with the lock held.
+func (f *fakeBackend) findSession(name string) *gotmux.Session {
+	for _, s := range f.sessions {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Finds a window by id. Must be called with the lock held.
+func (f *fakeBackend) findWindow(id string) *gotmux.Window {
+	for _, windows := range f.windows {
+		for _, w := range windows {
+			if w.Id == id {
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+// Gets the id of the window holding the pane. Must be called with the lock held.
+func (f *fakeBackend) paneWindow(paneId string) string {
+	for windowId, panes := range f.panes {
+		for _, p := range panes {
+			if p.Id == paneId {
+				return windowId
+			}
+		}
+	}
+	return ""
+}
+
+// Gets the links of the window, one per session it is linked to. Must be called with the lock held.
+func (f *fakeBackend) windowLinks(id string) []*gotmux.Window {
+	out := make([]*gotmux.Window, 0)
+	for _, s := range f.sessions {
+		for _, w := range f.windows[s.Id] {
+			if w.Id == id {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// Links the window to the session at the next free index. Must be called with the lock held.
+func (f *fakeBackend) link(w *gotmux.Window, s *gotmux.Session) {
+	// next index is one past the highest
+	idx := 0
+	for _, cur := range f.windows[s.Id] {
+		idx = max(idx, cur.Index+1)
+	}
+
+	// each link has its own index and active flag
+	c := *w
+	c.Index = idx
+	c.Active = false
+	f.windows[s.Id] = append(f.windows[s.Id], &c)
+	f.updateLinks(w.Id)
+}
+
+// Unlinks the window at the position from the session, which is killed if it was its last window.
+// Must be called with the lock held.
+func (f *fakeBackend) unlink(s *gotmux.Session, idx int) {
+	id := f.windows[s.Id][idx].Id
+	f.windows[s.Id] = slices.Delete(f.windows[s.Id], idx, idx+1)
+	if len(f.windows[s.Id]) == 0 {
+		f.removeSession(s)
+	}
+	f.updateLinks(id)
+}
+
+// Updates the linked sessions of all the links of the window. Must be called with the lock held.
+func (f *fakeBackend) updateLinks(id string) {
+	names := make([]string, 0)
+	for _, s := range f.sessions {
+		if slices.ContainsFunc(f.windows[s.Id], func(w *gotmux.Window) bool { return w.Id == id }) {
+			names = append(names, s.Name)
+		}
+	}
+
+	for _, w := range f.windowLinks(id) {
+		w.Linked = len(names) > 1
+		w.LinkedSessions = len(names)
+		w.LinkedSessionsList = slices.Clone(names)
+	}
+}
+
+// Adds a window with a single pane to the session. Must be called with the lock held.
+func (f *fakeBackend) addWindow(s *gotmux.Session, name string, startDir string, command string) *gotmux.Window {
+	id := f.nextWindow
+	f.nextWindow++
+
+	// next index is one past the highest
+	windows := f.windows[s.Id]
+	idx := 0
+	for _, w := range windows {
+		idx = max(idx, w.Index+1)
+	}
+
+	if name == "" {
+		name = "bash"
+	}
+
+	w := &gotmux.Window{
+		Id:                 "@" + strconv.Itoa(id),
+		Index:              idx,
+		Name:               name,
+		Active:             len(windows) == 0,
+		Activity:           "0",
+		LinkedSessions:     1,
+		LinkedSessionsList: []string{s.Name},
+	}
+	f.windows[s.Id] = append(windows, w)
+	f.addPane(w.Id, startDir, command)
+	return w
+}
+
+// Adds a pane to the window. Must be called with the lock held.
+func (f *fakeBackend) addPane(windowId string, startDir string, command string) *gotmux.Pane {
+	id := f.nextPane
+	f.nextPane++
+
+	if command == "" {
+		command = "bash"
+	}
+
+	panes := f.panes[windowId]
+	p := &gotmux.Pane{
+		Id:             "%" + strconv.Itoa(id),
+		Index:          len(panes),
+		Active:         len(panes) == 0,
+		CurrentCommand: command,
+		CurrentPath:    startDir,
+		StartCommand:   command,
+		StartPath:      startDir,
+
+		// the size of a window of a detached session
+		Left:   "0",
+		Top:    "0",
+		Width:  80,
+		Height: 24,
+	}
+	f.panes[windowId] = append(panes, p)
+	return p
+}
+
+// Removes the window, and the session owning it if it was its last window.
+// Must be called with the lock held.
+func (f *fakeBackend) removeWindow(id string) {
+	for _, p := range f.panes[id] {
+		delete(f.content, p.Id)
+	}
+	delete(f.panes, id)
+
+	for sessionId, windows := range f.windows {
+		for idx, w := range windows {
+			if w.Id != id {
+				continue
+			}
+
+			f.windows[sessionId] = append(windows[:idx:idx], windows[idx+1:]...)
+			if len(f.windows[sessionId]) == 0 {
+				for _, s := range f.sessions {
+					if s.Id == sessionId {
+						f.removeSession(s)
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+}
+
+// Removes the session and all its windows. Must be called with the lock held.
+func (f *fakeBackend) removeSession(s *gotmux.Session) {
+	windows := f.windows[s.Id]
+	delete(f.windows, s.Id)
+	for idx, cur := range f.sessions {
+		if cur == s {
+			f.sessions = append(f.sessions[:idx:idx], f.sessions[idx+1:]...)
+			break
+		}
+	}
+
+	// the windows linked to other sessions survive
+	for _, w := range windows {
+		if len(f.windowLinks(w.Id)) > 0 {
+			f.updateLinks(w.Id)
+			continue
+		}
+
+		for _, p := range f.panes[w.Id] {
+			delete(f.content, p.Id)
+		}
+		delete(f.panes, w.Id)
+	}
+}
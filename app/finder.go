@@ -0,0 +1,183 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Session, window or pane with their parents, used to jump to or attach to an item.
+// The keybindings shared by the views get the selected item from a func returning a target,
+// nil if nothing is selected.
+type target struct {
+	session *gotmux.Session
+	window  *gotmux.Window
+	pane    *gotmux.Pane
+}
+
+// Gets the type of the item, shown by the finder next to its text.
+func (t *target) label() string {
+	switch {
+	case t.pane != nil:
+		return "pane"
+	case t.window != nil:
+		return "window"
+	default:
+		return "session"
+	}
+}
+
+// Gets the text matched by the finder: the names, command, path and title of the item.
+func (t *target) text() string {
+	switch {
+	case t.pane != nil:
+		p := t.pane
+		return fmt.Sprintf("%s:%d.%d  %s  %s  %s", t.session.Name, t.window.Index, p.Index, p.CurrentCommand, p.CurrentPath, p.Title)
+	case t.window != nil:
+		return fmt.Sprintf("%s:%d  %s", t.session.Name, t.window.Index, t.window.Name)
+	default:
+		return t.session.Name
+	}
+}
+
+// Keybinding opening the fuzzy finder, shared by the views.
+func (a *App) finderKeybinding() *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    '/',
+			display: "/",
+		},
+		action:      "find",
+		description: "Find session, window or pane",
+		handler:     a.find,
+	}
+}
+
+// Opens the fuzzy finder over all the sessions, windows and panes.
+// Enter moves the cursor of the visible view to the chosen item, Ctrl-O attaches to it.
+func (a *App) find() {
+	items, err := a.targets()
+	if err != nil {
+		a.ui.error(err)
+		return
+	}
+
+	labels := make([]string, len(items))
+	candidates := make([]string, len(items))
+	for idx, item := range items {
+		labels[idx] = item.label()
+		candidates[idx] = item.text()
+	}
+
+	keys := []tcell.Key{tcell.KeyCtrlO}
+	a.ui.finder("Find (Enter: jump, Ctrl-O: attach)", labels, candidates, keys, func(idx int, key tcell.Key) {
+		item := items[idx]
+		var err error
+		switch {
+		case key == tcell.KeyCtrlO && item.window == nil:
+			err = a.attach(item.session)
+		case key == tcell.KeyCtrlO:
+			err = a.attachPane(item.session, item.window, item.pane)
+		default:
+			err = a.jump(item)
+		}
+		if err != nil {
+			a.ui.error(err)
+		}
+	})
+}
+
+// Lists all the sessions, windows and panes, each followed by its children.
+func (a *App) targets() ([]*target, error) {
+	sessions, err := a.backend.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*target, 0)
+	for _, session := range sessions {
+		items = append(items, &target{session: session})
+		windows, err := a.backend.ListWindows(session)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, window := range windows {
+			items = append(items, &target{session: session, window: window})
+			panes, err := a.backend.ListPanes(window)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, pane := range panes {
+				items = append(items, &target{session: session, window: window, pane: pane})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// Moves the cursor of the visible view to the item and previews it.
+func (a *App) jump(item *target) error {
+	var windowId, paneId string
+	if item.window != nil {
+		windowId = item.window.Id
+	}
+	if item.pane != nil {
+		paneId = item.pane.Id
+	}
+	gone := errors.New("can't find " + item.label() + " " + item.text())
+
+	switch page, _ := a.tabs.GetFrontPage(); page {
+	case "tree":
+		// the item may be newer than the tree
+		if err := a.tree.sync(); err != nil {
+			return err
+		}
+
+		node := a.tree.reveal(item.session.Id, windowId, paneId)
+		if node == nil {
+			return gone
+		}
+		a.tree.SetCurrentNode(node)
+
+		window, pane, err := a.tree.previewed(node)
+		if err != nil {
+			return err
+		}
+		a.preview.show(window, pane)
+		a.ui.SetFocus(a.tree)
+
+	case "panel":
+		p := a.panel
+		if _, err := p.sync(); err != nil {
+			return err
+		}
+
+		// selecting a row syncs the tables below it and the preview
+		var focus tview.Primitive = p.sessions
+		if !p.sessions.selectKey(item.session.Id) {
+			return gone
+		}
+		if windowId != "" {
+			if !p.windows.selectKey(windowId) {
+				return gone
+			}
+			focus = p.windows
+		}
+		if paneId != "" {
+			if !p.panes.selectKey(paneId) {
+				return gone
+			}
+			focus = p.panes
+		}
+		a.ui.SetFocus(focus)
+	}
+
+	return nil
+}
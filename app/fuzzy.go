@@ -0,0 +1,83 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores of the fuzzy matching.
+const (
+	fuzzyMatchScore       = 1
+	fuzzyConsecutiveBonus = 4
+	fuzzyBoundaryBonus    = 3
+	fuzzyGapPenalty       = 1
+)
+
+// Matches the pattern against the text as a case insensitive subsequence.
+// Returns the score of the match, higher is better, and if the text matched at all.
+// Consecutive characters and characters at the start of words score higher.
+func fuzzyScore(pattern string, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	pi := 0
+	last := -1
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+
+		score += fuzzyMatchScore
+		switch {
+		case last >= 0 && ti == last+1:
+			score += fuzzyConsecutiveBonus
+		case last >= 0:
+			score -= min(ti-last-1, fuzzyConsecutiveBonus) * fuzzyGapPenalty
+		}
+
+		// start of the text or of a word
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += fuzzyBoundaryBonus
+		}
+
+		last = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// Filters the candidates matching the pattern.
+// Returns the indexes of the matching candidates, best first. Ties keep the original order.
+func fuzzyFilter(pattern string, candidates []string) []int {
+	type match struct {
+		idx   int
+		score int
+	}
+
+	matches := make([]match, 0, len(candidates))
+	for idx, c := range candidates {
+		if score, ok := fuzzyScore(pattern, c); ok {
+			matches = append(matches, match{idx, score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return b.score - a.score
+	})
+
+	out := make([]int, len(matches))
+	for i, m := range matches {
+		out[i] = m.idx
+	}
+	return out
+}
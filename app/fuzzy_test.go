@@ -0,0 +1,77 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	cases := []struct {
+		pattern string
+		text    string
+		ok      bool
+	}{
+		{"", "anything", true},
+		{"abc", "a-b-c", true},
+		{"ABC", "abc", true},
+		{"abc", "acb", false},
+		{"abcd", "abc", false},
+	}
+	for _, c := range cases {
+		if _, ok := fuzzyScore(c.pattern, c.text); ok != c.ok {
+			t.Fatalf("fuzzyScore(%q, %q) matched = %v, want %v", c.pattern, c.text, ok, c.ok)
+		}
+	}
+
+	// consecutive characters and starts of words score higher
+	better := [][3]string{
+		{"log", "logs", "l_o_g"},
+		{"log", "my logs", "mylogs"},
+	}
+	for _, b := range better {
+		high, _ := fuzzyScore(b[0], b[1])
+		low, _ := fuzzyScore(b[0], b[2])
+		if high <= low {
+			t.Fatalf("score of %q in %q = %d, want more than %d in %q", b[0], b[1], high, low, b[2])
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	candidates := []string{"build", "b-u-i-l-d", "rebuild", "tests", "build"}
+	got := fuzzyFilter("build", candidates)
+	want := []int{0, 4, 2, 1}
+	if !slices.Equal(got, want) {
+		t.Fatalf("fuzzyFilter = %v, want %v", got, want)
+	}
+
+	if got := fuzzyFilter("", candidates); len(got) != len(candidates) {
+		t.Fatalf("empty pattern matched %d candidates, want all", len(got))
+	}
+}
+
+func TestFinderMatchesNamesOnly(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "other", "pan")
+	a := newTestApp(t, f)
+
+	items, err := a.targets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates := make([]string, len(items))
+	for idx, item := range items {
+		candidates[idx] = item.text()
+	}
+
+	// the type labels are not matched, so only the items of the pan session match
+	got := fuzzyFilter("pan", candidates)
+	if len(got) == 0 || items[got[0]].label() != "session" || items[got[0]].session.Name != "pan" {
+		t.Fatalf("best match of %q = %v, want the session pan", "pan", got)
+	}
+	for _, idx := range got {
+		if name := items[idx].session.Name; name != "pan" {
+			t.Fatalf("%s %q of session %s matched %q", items[idx].label(), candidates[idx], name, "pan")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Special keys by lowercase name, i.e. enter, esc, tab, up or f1.
+var specialKeys = func() map[string]tcell.Key {
+	keys := make(map[string]tcell.Key)
+	for key, name := range tcell.KeyNames {
+		// control keys are written with the ctrl modifier instead
+		if strings.Contains(name, "-") {
+			continue
+		}
+		keys[strings.ToLower(name)] = key
+	}
+	return keys
+}()
+
+// Parses the keys of a binding in the config, a sequence of keys separated by spaces,
+// i.e. "D", "ctrl+d", "alt+x", "shift+up" or "g g".
+func parseKeys(spec string) (*Key, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, errors.New("no keys")
+	}
+
+	var first, last *Key
+	for _, field := range fields {
+		key, err := parseKey(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if first == nil {
+			first = key
+		} else {
+			last.next = key
+		}
+		last = key
+	}
+
+	// the whole sequence is displayed with the first key
+	first.display = strings.Join(fields, " ")
+	return first, nil
+}
+
+// Parses a single key with its modifiers, a character, space or the name of a special key.
+func parseKey(s string) (*Key, error) {
+	// the key itself may be a plus
+	name := s
+	var mods []string
+	if idx := strings.LastIndex(s[:len(s)-1], "+"); idx >= 0 {
+		mods = strings.Split(s[:idx], "+")
+		name = s[idx+1:]
+	}
+
+	var mod tcell.ModMask
+	for _, m := range mods {
+		switch strings.ToLower(m) {
+		case "ctrl":
+			mod |= tcell.ModCtrl
+		case "alt":
+			mod |= tcell.ModAlt
+		case "shift":
+			mod |= tcell.ModShift
+		default:
+			return nil, fmt.Errorf("unknown modifier %s in %s", m, s)
+		}
+	}
+
+	if strings.ToLower(name) == "space" {
+		name = " "
+	}
+
+	runes := []rune(name)
+	if len(runes) > 1 {
+		key, ok := specialKeys[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown key: %s", s)
+		}
+
+		// enter, tab, backspace and esc are control characters, only alt is told apart
+		if key < tcell.KeyRune && mod&^tcell.ModAlt != 0 {
+			return nil, fmt.Errorf("%s only combines with alt: %s", name, s)
+		}
+		return &Key{key: key, mod: mod, display: s}, nil
+	}
+
+	r := runes[0]
+	if mod&tcell.ModShift != 0 {
+		r = unicode.ToUpper(r)
+	}
+
+	// ctrl with a letter is a control key, and shift is part of the rune
+	if mod&tcell.ModCtrl != 0 {
+		lower := unicode.ToLower(r)
+		if lower < 'a' || lower > 'z' {
+			return nil, fmt.Errorf("ctrl only combines with letters and special keys: %s", s)
+		}
+
+		// the terminal sends the same characters for these keys as for backspace, tab and enter
+		if lower == 'h' || lower == 'i' || lower == 'm' {
+			return nil, fmt.Errorf("%s can't be told apart from backspace, tab or enter", s)
+		}
+		return &Key{key: tcell.KeyCtrlA + tcell.Key(lower-'a'), mod: mod & tcell.ModAlt, display: s}, nil
+	}
+
+	return &Key{key: tcell.KeyRune, rune: r, mod: mod & tcell.ModAlt, display: s}, nil
+}
+
+// Checks if the keys of the sequence are the same as the first keys of the other sequence.
+func (k *Key) prefixOf(other *Key) bool {
+	for ; k != nil; k, other = k.next, other.next {
+		if other == nil || k.key != other.key || k.rune != other.rune || k.mod != other.mod {
+			return false
+		}
+	}
+	return true
+}
+
+// Finds the bindings that can't be told apart, because they have the same keys
+// or the keys of one start the keys of the other.
+func (k KeybdindingHolder) conflicts() []error {
+	errs := make([]error, 0)
+	for i, a := range k {
+		for _, b := range k[i+1:] {
+			// an action may have several keys
+			if a.handler == nil || b.handler == nil || a.action == b.action {
+				continue
+			}
+
+			if a.key.prefixOf(b.key) || b.key.prefixOf(a.key) {
+				errs = append(errs, fmt.Errorf("%s (%s) conflicts with %s (%s)", a.key.display, a.action, b.key.display, b.action))
+			}
+		}
+	}
+	return errs
+}
+
+// Keybindings of a view, listed by the command palette.
+type viewBindings struct {
+	// page of the tabs showing the view, and name of the view
+	page string
+	name string
+
+	view tview.Primitive
+	keys KeybdindingHolder
+}
+
+// Applies the keys of the config to the keybindings of a view and records the conflicts.
+// Returns the func handling the events of the view.
+func (a *App) bind(page string, name string, view tview.Primitive, kh KeybdindingHolder) func(event *tcell.EventKey) bool {
+	a.bindings = append(a.bindings, &viewBindings{page, name, view, kh})
+
+	for _, binding := range kh {
+		if binding.action == "" {
+			continue
+		}
+
+		a.actions[binding.action] = true
+		if key, ok := conf.keys[binding.action]; ok {
+			binding.key = key
+		}
+	}
+
+	// the same conflict may be found in several views
+	for _, err := range kh.conflicts() {
+		found := slices.ContainsFunc(a.keyErrs, func(e error) bool {
+			return e.Error() == err.Error()
+		})
+		if !found {
+			a.keyErrs = append(a.keyErrs, err)
+		}
+	}
+
+	return kh.handler()
+}
+
+// Gets the errors of the keys of the config, once all the views are built.
+func (a *App) keyErrors() []error {
+	errs := slices.Clone(a.keyErrs)
+	for _, action := range slices.Sorted(maps.Keys(conf.keys)) {
+		if !a.actions[action] {
+			errs = append(errs, fmt.Errorf("unknown action: %s", action))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKeys(t *testing.T) {
+	for _, c := range []struct {
+		spec  string
+		key   tcell.Key
+		r     rune
+		mod   tcell.ModMask
+		count int
+	}{
+		{"D", tcell.KeyRune, 'D', tcell.ModNone, 1},
+		{"shift+d", tcell.KeyRune, 'D', tcell.ModNone, 1},
+		{"ctrl+d", tcell.KeyCtrlD, 0, tcell.ModNone, 1},
+		{"alt+x", tcell.KeyRune, 'x', tcell.ModAlt, 1},
+		{"shift+up", tcell.KeyUp, 0, tcell.ModShift, 1},
+		{"ctrl+F1", tcell.KeyF1, 0, tcell.ModCtrl, 1},
+		{"alt+enter", tcell.KeyEnter, 0, tcell.ModAlt, 1},
+		{"+", tcell.KeyRune, '+', tcell.ModNone, 1},
+		{"alt++", tcell.KeyRune, '+', tcell.ModAlt, 1},
+		{"space", tcell.KeyRune, ' ', tcell.ModNone, 1},
+		{"g g", tcell.KeyRune, 'g', tcell.ModNone, 2},
+	} {
+		k, err := parseKeys(c.spec)
+		if err != nil {
+			t.Fatalf("parseKeys(%q): %v", c.spec, err)
+		}
+		if k.key != c.key || k.rune != c.r || k.mod != c.mod {
+			t.Fatalf("parseKeys(%q) = %v %q %v, want %v %q %v", c.spec, k.key, k.rune, k.mod, c.key, c.r, c.mod)
+		}
+		if k.display != c.spec {
+			t.Fatalf("display = %q, want %q", k.display, c.spec)
+		}
+
+		count := 0
+		for ; k != nil; k = k.next {
+			count++
+		}
+		if count != c.count {
+			t.Fatalf("parseKeys(%q) has %d keys, want %d", c.spec, count, c.count)
+		}
+	}
+}
+
+func TestParseKeysInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"hyper+x",
+		"ctrl+1",
+		"pageup2",
+		"shift+enter",
+		"ctrl+tab",
+		"ctrl+h",
+		"ctrl+i",
+		"ctrl+m",
+	} {
+		if _, err := parseKeys(spec); err == nil {
+			t.Fatalf("parseKeys(%q) should fail", spec)
+		}
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	for _, c := range []struct {
+		spec  string
+		event *tcell.EventKey
+		want  bool
+	}{
+		{"D", tcell.NewEventKey(tcell.KeyRune, 'D', tcell.ModShift), true},
+		{"d", tcell.NewEventKey(tcell.KeyRune, 'D', tcell.ModShift), false},
+		{"alt+x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), true},
+		{"alt+x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), false},
+		{"ctrl+d", tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl), true},
+		{"shift+up", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift), true},
+		{"shift+up", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), false},
+		{"up", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift), false},
+		{"ctrl+up", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), false},
+		{"enter", tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), true},
+	} {
+		k, err := parseKeys(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := k.matches(c.event); got != c.want {
+			t.Fatalf("%q matches %v = %v, want %v", c.spec, c.event.Name(), got, c.want)
+		}
+	}
+}
+
+func TestKeyConflicts(t *testing.T) {
+	binding := func(spec string, action string) *Keybinding {
+		k, err := parseKeys(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Keybinding{key: k, action: action, handler: func() {}}
+	}
+
+	kh := KeybdindingHolder{
+		binding("g g", "top"),
+		binding("g", "go"),
+		binding("shift+up", "move-up"),
+		binding("up", "up"),
+		binding("x", "kill"),
+		binding("x", "kill"),
+	}
+	errs := kh.conflicts()
+	if len(errs) != 1 || errs[0].Error() != "g g (top) conflicts with g (go)" {
+		t.Fatalf("conflicts = %v, want only g g with g", errs)
+	}
+}
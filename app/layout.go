@@ -0,0 +1,154 @@
+package app
+
+import (
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Preset layouts of tmux, in the order of the next-layout command.
+var layouts = []string{"even-horizontal", "even-vertical", "main-horizontal", "main-vertical", "tiled"}
+
+// Keybinding choosing the layout of the window, shared by the views.
+func (a *App) layoutKeybinding(selected func() *target) *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    'T',
+			display: "T",
+		},
+		action:      "layout",
+		description: "Choose the layout of the window, applied live",
+		handler: func() {
+			item := selected()
+			if item == nil || item.window == nil {
+				return
+			}
+
+			// the current layout is kept to restore it on cancel
+			window, err := a.backend.GetWindow(item.window.Id)
+			if err != nil {
+				a.ui.error(err)
+				return
+			}
+			if window == nil {
+				return
+			}
+			original := window.Layout
+
+			apply := func(layout string) {
+				if err := a.backend.SelectLayout(window, layout); err != nil {
+					a.ui.error(err)
+				}
+				a.refresh()
+			}
+
+			a.ui.picker("Layout of "+window.Name, layouts, func(idx int) {
+				apply(layouts[idx])
+			}, func(idx int, ok bool) {
+				switch {
+				case ok:
+					apply(layouts[idx])
+				case original != "":
+					apply(original)
+				}
+			})
+		},
+	}
+}
+
+// Keybindings resizing, zooming and breaking out the pane, shared by the views.
+func (a *App) paneLayoutKeybindings(selected func() *target) []*Keybinding {
+	// gets the selected pane, nil if a session or window is selected
+	selectedPane := func() *target {
+		item := selected()
+		if item == nil || item.session == nil || item.pane == nil {
+			return nil
+		}
+		return item
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    's',
+				display: "s",
+			},
+			action:      "resize-pane",
+			description: "Resize the pane with the arrows",
+			handler: func() {
+				item := selectedPane()
+				if item == nil {
+					return
+				}
+
+				a.ui.keyModal("Resize pane", "Arrows - Resize by 1  |  Shift+Arrows - Resize by 5", func(event *tcell.EventKey) {
+					a.resizePane(item.pane, event)
+				})
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'z',
+				display: "z",
+			},
+			action:      "zoom-pane",
+			description: "Zoom or unzoom the pane",
+			handler: func() {
+				item := selectedPane()
+				if item == nil {
+					return
+				}
+
+				if err := a.backend.ZoomPane(item.pane); err != nil {
+					a.ui.error(err)
+				}
+				a.refresh()
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '!',
+				display: "!",
+			},
+			action:      "break-pane",
+			description: "Break the pane out into its own window",
+			handler: func() {
+				item := selectedPane()
+				if item == nil {
+					return
+				}
+
+				if _, err := a.backend.BreakPane(item.pane, item.session); err != nil {
+					a.ui.error(err)
+				}
+				a.refresh()
+			},
+		},
+	}
+}
+
+// Resizes the pane in the direction of the arrow key, by 5 cells with shift.
+func (a *App) resizePane(pane *gotmux.Pane, event *tcell.EventKey) {
+	directions := map[tcell.Key]string{
+		tcell.KeyLeft:  "L",
+		tcell.KeyRight: "R",
+		tcell.KeyUp:    "U",
+		tcell.KeyDown:  "D",
+	}
+	direction, ok := directions[event.Key()]
+	if !ok {
+		return
+	}
+
+	cells := 1
+	if event.Modifiers()&tcell.ModShift != 0 {
+		cells = 5
+	}
+	if err := a.backend.ResizePane(pane, direction, cells); err != nil {
+		a.ui.error(err)
+	}
+	a.refresh()
+}
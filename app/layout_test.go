@@ -0,0 +1,146 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Creates a session whose first window is split in two panes, %0 and %1.
+func newSplitSession(t *testing.T, f *fakeBackend) *gotmux.Session {
+	t.Helper()
+	s := newTestSessions(t, f, "alpha")[0]
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SelectLayout(&gotmux.Window{Id: "@0"}, "tiled"); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Gets the pane of the fake backend, failing the test if it is missing.
+func findPane(t *testing.T, f *fakeBackend, windowId string, paneId string) *gotmux.Pane {
+	t.Helper()
+	for _, p := range f.panes[windowId] {
+		if p.Id == paneId {
+			return p
+		}
+	}
+	t.Fatalf("pane %s not in window %s", paneId, windowId)
+	return nil
+}
+
+func TestLayoutPicker(t *testing.T) {
+	f := newFakeBackend()
+	s := newSplitSession(t, f)
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", ""))
+	press(a, tcell.KeyRune, 'T')
+	press(a, tcell.KeyDown, 0)
+	if layout := f.findWindow("@0").Layout; layout != layouts[1] {
+		t.Fatalf("live layout = %q, want %q", layout, layouts[1])
+	}
+	press(a, tcell.KeyDown, 0)
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+	if layout := f.findWindow("@0").Layout; layout != layouts[2] {
+		t.Fatalf("layout = %q, want %q", layout, layouts[2])
+	}
+}
+
+func TestLayoutPickerCancelled(t *testing.T) {
+	f := newFakeBackend()
+	s := newSplitSession(t, f)
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", ""))
+	press(a, tcell.KeyRune, 'T')
+	press(a, tcell.KeyDown, 0)
+	press(a, tcell.KeyEscape, 0)
+	assertNoModal(t, a)
+	if layout := f.findWindow("@0").Layout; layout != "tiled" {
+		t.Fatalf("layout = %q, want the original %q", layout, "tiled")
+	}
+}
+
+func TestResizePane(t *testing.T) {
+	f := newFakeBackend()
+	s := newSplitSession(t, f)
+	a := newTestApp(t, f)
+	pane := findPane(t, f, "@0", "%1")
+	width, height := pane.Width, pane.Height
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%1"))
+	press(a, tcell.KeyRune, 's')
+	press(a, tcell.KeyRight, 0)
+	press(a, tcell.KeyUp, 0)
+	if pane.Width != width+1 || pane.Height != height-1 {
+		t.Fatalf("size = %dx%d, want %dx%d", pane.Width, pane.Height, width+1, height-1)
+	}
+
+	// shift is not delivered by press, the modal passes the event as is
+	a.resizePane(pane, tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModShift))
+	if pane.Width != width-4 {
+		t.Fatalf("width = %d, want %d", pane.Width, width-4)
+	}
+
+	press(a, tcell.KeyEscape, 0)
+	assertNoModal(t, a)
+	if a.ui.GetFocus() != a.tree {
+		t.Fatal("tree not focused back")
+	}
+}
+
+func TestZoomPane(t *testing.T) {
+	f := newFakeBackend()
+	s := newSplitSession(t, f)
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%0"))
+	press(a, tcell.KeyRune, 'z')
+	assertNoModal(t, a)
+	if !f.findWindow("@0").ZoomedFlag {
+		t.Fatal("window not zoomed")
+	}
+	if !findPane(t, f, "@0", "%0").Active || findPane(t, f, "@0", "%1").Active {
+		t.Fatal("zoomed pane not active")
+	}
+
+	// a window selected has no pane to zoom
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", ""))
+	press(a, tcell.KeyRune, 'z')
+	if !f.findWindow("@0").ZoomedFlag {
+		t.Fatal("window unzoomed without a pane selected")
+	}
+}
+
+func TestBreakPane(t *testing.T) {
+	f := newFakeBackend()
+	s := newSplitSession(t, f)
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%1"))
+	press(a, tcell.KeyRune, '!')
+	assertNoModal(t, a)
+
+	windows, err := f.ListWindows(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("windows = %d, want 2", len(windows))
+	}
+	if got := paneIds(f, "@0"); len(got) != 1 || got[0] != "%0" {
+		t.Fatalf("panes of @0 = %v, want [%%0]", got)
+	}
+	broken := windows[1].Id
+	if got := paneIds(f, broken); len(got) != 1 || got[0] != "%1" {
+		t.Fatalf("panes of %s = %v, want [%%1]", broken, got)
+	}
+	if a.tree.reveal(s.Id, broken, "%1") == nil {
+		t.Fatalf("pane %%1 not in window %s of the tree", broken)
+	}
+}
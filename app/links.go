@@ -0,0 +1,189 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Window cut or copied from a session.
+type windowClip struct {
+	window  *gotmux.Window
+	session *gotmux.Session
+
+	// set if the window was copied, it is then linked to the session it is pasted in instead of moved
+	link bool
+}
+
+// Keybindings moving, linking and unlinking windows between sessions, shared by the views.
+func (a *App) linkKeybindings(selected func() *target) []*Keybinding {
+	// gets the selected window, nil if a session is selected
+	selectedWindow := func() *target {
+		item := selected()
+		if item == nil || item.session == nil || item.window == nil {
+			return nil
+		}
+		return item
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '>',
+				display: ">",
+			},
+			action:      "move-window",
+			description: "Move the window to another session",
+			handler: func() {
+				item := selectedWindow()
+				if item == nil {
+					return
+				}
+
+				// moving to a session the window is already in would link it twice
+				a.pickSession("Move "+item.window.Name+" to", linkedTo(item.window), func(session *gotmux.Session) {
+					a.moveWindow(item.window, item.session, session)
+				})
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '+',
+				display: "+",
+			},
+			action:      "link-window",
+			description: "Link the window to another session",
+			handler: func() {
+				item := selectedWindow()
+				if item == nil {
+					return
+				}
+
+				a.pickSession("Link "+item.window.Name+" to", linkedTo(item.window), func(session *gotmux.Session) {
+					a.linkWindow(item.window, session)
+				})
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '-',
+				display: "-",
+			},
+			action:      "unlink-window",
+			description: "Unlink the window from this session, if it is linked to others",
+			handler: func() {
+				item := selectedWindow()
+				if item == nil {
+					return
+				}
+
+				if err := a.backend.UnlinkWindow(item.window, item.session); err != nil {
+					a.ui.error(err)
+				}
+				a.refresh()
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'x',
+				display: "x",
+			},
+			action:      "cut-window",
+			description: "Cut the window, to move it to the session it is pasted in",
+			handler: func() {
+				if item := selectedWindow(); item != nil {
+					a.clipboard = &windowClip{window: item.window, session: item.session}
+				}
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'y',
+				display: "y",
+			},
+			action:      "copy-window",
+			description: "Copy the window, to link it to the session it is pasted in",
+			handler: func() {
+				if item := selectedWindow(); item != nil {
+					a.clipboard = &windowClip{window: item.window, session: item.session, link: true}
+				}
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'p',
+				display: "p",
+			},
+			action:      "paste-window",
+			description: "Paste the cut or copied window in the session",
+			handler: func() {
+				item := selected()
+				if item == nil || item.session == nil {
+					return
+				}
+
+				clip := a.clipboard
+				if clip == nil {
+					a.ui.message("Paste window", "Cut (x) or copy (y) a window first")
+					return
+				}
+
+				// the window may have changed or been killed since
+				window, err := a.backend.GetWindow(clip.window.Id)
+				if err != nil {
+					a.ui.error(err)
+					return
+				}
+				if window == nil {
+					a.clipboard = nil
+					a.ui.message("Paste window", clip.window.Name+" no longer exists")
+					return
+				}
+
+				if linkedTo(window)(item.session) {
+					a.ui.message("Paste window", window.Name+" is already in "+item.session.Name)
+					return
+				}
+
+				// a copied window can be pasted in several sessions
+				if clip.link {
+					a.linkWindow(window, item.session)
+					return
+				}
+
+				a.clipboard = nil
+				a.moveWindow(window, clip.session, item.session)
+			},
+		},
+	}
+}
+
+// Checks if the window is linked to a session, to skip the sessions it is already in.
+func linkedTo(window *gotmux.Window) func(session *gotmux.Session) bool {
+	return func(session *gotmux.Session) bool {
+		return slices.Contains(window.LinkedSessionsList, session.Name)
+	}
+}
+
+// Moves the window from a session to another, then refreshes the views.
+func (a *App) moveWindow(window *gotmux.Window, from *gotmux.Session, to *gotmux.Session) {
+	if err := a.backend.MoveWindow(window, from, to); err != nil {
+		a.ui.error(err)
+	}
+	a.refresh()
+}
+
+// Links the window to the session, then refreshes the views.
+func (a *App) linkWindow(window *gotmux.Window, session *gotmux.Session) {
+	if err := a.backend.LinkWindow(window, session); err != nil {
+		a.ui.error(err)
+	}
+	a.refresh()
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Gets the names of the windows of the session in the fake backend.
+func windowNames(t *testing.T, f *fakeBackend, sessionId string) []string {
+	t.Helper()
+	names := make([]string, 0)
+	for _, w := range f.windows[sessionId] {
+		names = append(names, w.Name)
+	}
+	return names
+}
+
+func TestMoveWindow(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[0], &gotmux.NewWindowOptions{WindowName: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	// the picker only lists the sessions the window is not in
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, w.Id, ""))
+	press(a, tcell.KeyRune, '>')
+	typeText(a, "beta")
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	if names := windowNames(t, f, sessions[0].Id); !slices.Equal(names, []string{"bash"}) {
+		t.Fatalf("windows of alpha = %v, want [bash]", names)
+	}
+	if names := windowNames(t, f, sessions[1].Id); !slices.Equal(names, []string{"bash", "logs"}) {
+		t.Fatalf("windows of beta = %v, want [bash logs]", names)
+	}
+	if a.tree.reveal(sessions[1].Id, w.Id, "") == nil {
+		t.Fatal("moved window not in beta in the tree")
+	}
+}
+
+func TestCopyPasteAndUnlinkWindow(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta", "gamma")
+	a := newTestApp(t, f)
+
+	// a copied window is linked to every session it is pasted in
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", ""))
+	press(a, tcell.KeyRune, 'y')
+	for _, s := range sessions[1:] {
+		a.tree.SetCurrentNode(a.tree.reveal(s.Id, "", ""))
+		press(a, tcell.KeyRune, 'p')
+		assertNoModal(t, a)
+	}
+
+	w, err := f.GetWindow("@0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(w.LinkedSessionsList, []string{"alpha", "beta", "gamma"}) {
+		t.Fatalf("linked sessions = %v, want all", w.LinkedSessionsList)
+	}
+
+	// pasting where the window already is does nothing
+	press(a, tcell.KeyRune, 'p')
+	if !a.ui.root.HasPage(modalName) {
+		t.Fatal("expected a message about the window already being there")
+	}
+	press(a, tcell.KeyEnter, 0)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[1].Id, "@0", ""))
+	press(a, tcell.KeyRune, '-')
+	assertNoModal(t, a)
+
+	if w, err = f.GetWindow("@0"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(w.LinkedSessionsList, []string{"alpha", "gamma"}) {
+		t.Fatalf("linked sessions = %v, want [alpha gamma]", w.LinkedSessionsList)
+	}
+	if a.tree.reveal(sessions[1].Id, "@0", "") != nil {
+		t.Fatal("unlinked window still in beta in the tree")
+	}
+}
+
+func TestCutPasteWindow(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[0], &gotmux.NewWindowOptions{WindowName: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, w.Id, ""))
+	press(a, tcell.KeyRune, 'x')
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[1].Id, "", ""))
+	press(a, tcell.KeyRune, 'p')
+	assertNoModal(t, a)
+
+	if names := windowNames(t, f, sessions[1].Id); !slices.Equal(names, []string{"bash", "logs"}) {
+		t.Fatalf("windows of beta = %v, want [bash logs]", names)
+	}
+	if a.clipboard != nil {
+		t.Fatal("a cut window is pasted once")
+	}
+}
+
+func TestPasteKilledWindow(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, w.Id, ""))
+	press(a, tcell.KeyRune, 'y')
+	if err := f.KillWindow(w); err != nil {
+		t.Fatal(err)
+	}
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[1].Id, "", ""))
+	press(a, tcell.KeyRune, 'p')
+	if a.clipboard != nil {
+		t.Fatal("the clipboard should be cleared once its window is gone")
+	}
+	if names := windowNames(t, f, sessions[1].Id); len(names) != 1 {
+		t.Fatalf("windows of beta = %v, want only its own", names)
+	}
+}
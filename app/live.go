@@ -0,0 +1,186 @@
+package app
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Period of the captures of the live pane.
+const liveRefresh = 100 * time.Millisecond
+
+// Names of the special keys in tmux, for the keys forwarded to the live pane.
+var tmuxKeys = map[tcell.Key]string{
+	tcell.KeyEnter:      "Enter",
+	tcell.KeyTab:        "Tab",
+	tcell.KeyBacktab:    "BTab",
+	tcell.KeyBackspace:  "BSpace",
+	tcell.KeyBackspace2: "BSpace",
+	tcell.KeyEscape:     "Escape",
+	tcell.KeyUp:         "Up",
+	tcell.KeyDown:       "Down",
+	tcell.KeyLeft:       "Left",
+	tcell.KeyRight:      "Right",
+	tcell.KeyHome:       "Home",
+	tcell.KeyEnd:        "End",
+	tcell.KeyPgUp:       "PPage",
+	tcell.KeyPgDn:       "NPage",
+	tcell.KeyInsert:     "IC",
+	tcell.KeyDelete:     "DC",
+	tcell.KeyF1:         "F1",
+	tcell.KeyF2:         "F2",
+	tcell.KeyF3:         "F3",
+	tcell.KeyF4:         "F4",
+	tcell.KeyF5:         "F5",
+	tcell.KeyF6:         "F6",
+	tcell.KeyF7:         "F7",
+	tcell.KeyF8:         "F8",
+	tcell.KeyF9:         "F9",
+	tcell.KeyF10:        "F10",
+	tcell.KeyF11:        "F11",
+	tcell.KeyF12:        "F12",
+}
+
+// Gets the key of tmux for the event, and if it is typed literally.
+// An empty key is returned for the keys tmux has no name for.
+func tmuxKey(event *tcell.EventKey) (string, bool) {
+	mods := event.Modifiers()
+	if event.Key() == tcell.KeyRune {
+		if mods&tcell.ModAlt != 0 {
+			return "M-" + string(event.Rune()), false
+		}
+		return string(event.Rune()), true
+	}
+
+	// the named keys come first, some of them are control keys, i.e. Tab is Ctrl-I
+	name, ok := tmuxKeys[event.Key()]
+	switch {
+	case ok:
+		if mods&tcell.ModShift != 0 {
+			name = "S-" + name
+		}
+		if mods&tcell.ModCtrl != 0 {
+			name = "C-" + name
+		}
+	case event.Key() >= tcell.KeyCtrlA && event.Key() <= tcell.KeyCtrlZ:
+		name = "C-" + string(rune('a'+event.Key()-tcell.KeyCtrlA))
+	default:
+		return "", false
+	}
+
+	if mods&tcell.ModAlt != 0 {
+		name = "M-" + name
+	}
+	return name, false
+}
+
+// Keybinding typing in the previewed pane from the preview, shared by the views.
+func (a *App) liveKeybinding() *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    'P',
+			display: "P",
+		},
+		action:      "live-pane",
+		description: "Type in the previewed pane from the preview, with its output refreshed live",
+		handler:     a.openLive,
+	}
+}
+
+// Forwards the keys to the live pane, except the keys leaving it.
+// The keys are caught before the views, Ctrl-C would stop the app otherwise.
+func (a *App) initLive() {
+	p := a.preview
+	kh := KeybdindingHolder([]*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyCtrlRightSq,
+				display: "Ctrl-]",
+			},
+			action:      "leave-pane",
+			description: "Stop typing in the pane",
+			handler:     a.closeLive,
+		},
+	})
+
+	// apply the keys of the config
+	handle := a.bind("", "live pane", p, kh)
+	p.leave = kh[0].key
+
+	a.ui.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// modals opened over the live pane get the keys
+		if !p.live || a.ui.GetFocus() != p {
+			return event
+		}
+
+		if !handle(event) {
+			a.forward(event)
+		}
+		return nil
+	})
+}
+
+// Focuses the preview and forwards the keys to the previewed pane,
+// capturing it continuously until the leave key is pressed.
+func (a *App) openLive() {
+	p := a.preview
+	if p.pane == nil || p.history || p.live {
+		return
+	}
+
+	// the active pane is typed in when the layout of a window is previewed
+	p.set(p.pane, nil, nil)
+	p.live = true
+	p.refresh()
+	p.back = a.ui.GetFocus()
+	p.SetTitle(surroundSpace("Live " + p.pane.Id + " - " + p.leave.display + " to leave"))
+	a.ui.SetFocus(p)
+
+	stop := make(chan struct{})
+	p.stopLive = stop
+	go func() {
+		ticker := time.NewTicker(liveRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				a.ui.QueueUpdateDraw(p.refresh)
+			}
+		}
+	}()
+}
+
+// Stops forwarding the keys, the preview follows the selection again.
+func (a *App) closeLive() {
+	p := a.preview
+	if !p.live {
+		return
+	}
+
+	p.live = false
+	close(p.stopLive)
+	p.stopLive = nil
+	p.SetTitle(surroundSpace("Preview"))
+	if p.back != nil {
+		a.ui.SetFocus(p.back)
+		p.back = nil
+	}
+	a.refresh()
+}
+
+// Sends the key of the event to the live pane, leaving it if it can't be sent.
+func (a *App) forward(event *tcell.EventKey) {
+	p := a.preview
+	key, literal := tmuxKey(event)
+	if key == "" {
+		return
+	}
+
+	if err := a.backend.SendKeys(p.pane, literal, key); err != nil {
+		a.closeLive()
+		a.ui.error(err)
+	}
+}
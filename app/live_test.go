@@ -0,0 +1,62 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestTmuxKey(t *testing.T) {
+	cases := []struct {
+		event   *tcell.EventKey
+		key     string
+		literal bool
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), "a", true},
+		{tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone), ";", true},
+		{tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), "M-x", false},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), "Enter", false},
+		{tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), "Tab", false},
+		{tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl), "C-c", false},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift), "S-Up", false},
+		{tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModCtrl|tcell.ModAlt), "M-C-Left", false},
+		{tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone), "NPage", false},
+		{tcell.NewEventKey(tcell.KeyF13, 0, tcell.ModNone), "", false},
+	}
+	for _, c := range cases {
+		key, literal := tmuxKey(c.event)
+		if key != c.key || literal != c.literal {
+			t.Fatalf("tmuxKey(%s) = %q %v, want %q %v", c.event.Name(), key, literal, c.key, c.literal)
+		}
+	}
+}
+
+func TestLivePane(t *testing.T) {
+	f := newFakeBackend()
+	s := newTestSessions(t, f, "alpha")[0]
+	a := newTestApp(t, f)
+	p := a.preview
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%0"))
+	p.show(nil, &gotmux.Pane{Id: "%0"})
+	press(a, tcell.KeyRune, 'P')
+	if !p.live || a.ui.GetFocus() != p {
+		t.Fatal("live pane not opened")
+	}
+
+	// the keys of the views are forwarded too, Ctrl-C included
+	typeText(a, "ls q")
+	press(a, tcell.KeyEnter, 0)
+	press(a, tcell.KeyCtrlC, 0)
+	press(a, tcell.KeyCtrlRightSq, 0)
+	if p.live || a.ui.GetFocus() != a.tree {
+		t.Fatal("live pane not left")
+	}
+
+	want := []string{"l", "s", " ", "q", "Enter", "C-c"}
+	if got := f.keys["%0"]; !slices.Equal(got, want) {
+		t.Fatalf("keys = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,435 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Prefix of the marked items in the views.
+const markPrefix = "● "
+
+// View whose items can be marked, to run the bulk actions on them.
+type marker interface {
+	toggleMark()
+	markAll()
+	invertMarks()
+	clearMarks()
+
+	// gets the marked items, with their session and window
+	markedTargets() []*target
+}
+
+// Checks if the value is marked.
+func (t *Table[T]) isMarked(v *T) bool {
+	return t.key != nil && t.marked[t.key(v)]
+}
+
+// Sets the marks of the values, then redraws the rows.
+func (t *Table[T]) setMarks(values []*T, mark func(marked bool) bool) {
+	if t.key == nil || t.col == nil {
+		return
+	}
+
+	for _, v := range values {
+		t.marked[t.key(v)] = mark(t.isMarked(v))
+	}
+	t.setRows(t.values, t.col)
+}
+
+// Toggles the mark of the selected value.
+func (t *Table[T]) toggleMark() {
+	if v := t.getSelected(); v != nil {
+		t.setMarks([]*T{v}, func(marked bool) bool { return !marked })
+	}
+}
+
+// Marks all the values of the table.
+func (t *Table[T]) markAll() {
+	t.setMarks(t.values, func(bool) bool { return true })
+}
+
+// Inverts the marks of all the values of the table.
+func (t *Table[T]) invertMarks() {
+	t.setMarks(t.values, func(marked bool) bool { return !marked })
+}
+
+// Unmarks all the values of the table.
+func (t *Table[T]) clearMarks() {
+	t.setMarks(t.values, func(bool) bool { return false })
+}
+
+// Gets the marked values, in the order of the rows.
+func (t *Table[T]) markedValues() []*T {
+	out := make([]*T, 0)
+	for _, v := range t.values {
+		if t.isMarked(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Marker over a table, turning its values into items.
+type tableMarker[T any] struct {
+	*Table[T]
+	target func(v *T) *target
+}
+
+func (m *tableMarker[T]) markedTargets() []*target {
+	out := make([]*target, 0)
+	for _, v := range m.markedValues() {
+		if item := m.target(v); item != nil {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Sets the mark of the node and redraws it.
+func (t *Tree) setMark(node *tview.TreeNode, marked bool) {
+	if tn := unwrapNode(node); tn != nil {
+		tn.marked = marked
+		node.SetText(tn.title())
+		node.SetColor(tn.color())
+	}
+}
+
+// Toggles the mark of the current node.
+func (t *Tree) toggleMark() {
+	node := t.GetCurrentNode()
+	if tn := unwrapNode(node); tn != nil {
+		t.setMark(node, !tn.marked)
+	}
+}
+
+// Sets the marks of the displayed nodes of the same type as the current node.
+func (t *Tree) setMarks(mark func(marked bool) bool) {
+	cur := unwrapNode(t.GetCurrentNode())
+	if cur == nil {
+		return
+	}
+
+	t.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if tn := unwrapNode(node); tn != nil && tn.typ == cur.typ {
+			t.setMark(node, mark(tn.marked))
+		}
+		return true
+	})
+}
+
+// Marks all the displayed sessions, windows or panes, depending on the current node.
+func (t *Tree) markAll() {
+	t.setMarks(func(bool) bool { return true })
+}
+
+// Inverts the marks of the displayed sessions, windows or panes, depending on the current node.
+func (t *Tree) invertMarks() {
+	t.setMarks(func(marked bool) bool { return !marked })
+}
+
+// Unmarks all the nodes, including the ones hidden by the filter.
+func (t *Tree) clearMarks() {
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		t.setMark(sessionNode, false)
+		for _, windowNode := range t.children(sessionNode) {
+			t.setMark(windowNode, false)
+			for _, paneNode := range t.children(windowNode) {
+				t.setMark(paneNode, false)
+			}
+		}
+	}
+}
+
+// Gets the marked nodes as items, including the ones hidden by the filter.
+func (t *Tree) markedTargets() []*target {
+	out := make([]*target, 0)
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		session := unwrapNode(sessionNode)
+		if session.marked {
+			out = append(out, &target{session: session.session()})
+		}
+
+		for _, windowNode := range t.children(sessionNode) {
+			window := unwrapNode(windowNode)
+			if window.marked {
+				out = append(out, &target{session: session.session(), window: window.window()})
+			}
+
+			for _, paneNode := range t.children(windowNode) {
+				pane := unwrapNode(paneNode)
+				if pane.marked {
+					out = append(out, &target{session: session.session(), window: window.window(), pane: pane.pane()})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Keybindings marking the items of the view and running the bulk actions on them, shared by the views.
+func (a *App) markKeybindings(m marker) []*Keybinding {
+	// runs a bulk action on the marked items
+	bulk := func(action func(items []*target)) func() {
+		return func() {
+			items := m.markedTargets()
+			if len(items) == 0 {
+				a.ui.message("Bulk action", "Nothing is marked, mark items with m first")
+				return
+			}
+			action(items)
+		}
+	}
+
+	// runs the steps of the plan once confirmed, then clears the marks and refreshes the views
+	run := func(title string, plan []*bulkStep) {
+		if len(plan) == 0 {
+			a.ui.message(title, "None of the marked items applies")
+			return
+		}
+
+		lines := make([]string, len(plan))
+		for idx, step := range plan {
+			lines[idx] = step.text
+		}
+		a.ui.confirmPlan(title, lines, func() {
+			errs := make([]error, 0)
+			for _, step := range plan {
+				if err := step.run(); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", step.text, err))
+				}
+			}
+
+			m.clearMarks()
+			a.refresh()
+			if err := errors.Join(errs...); err != nil {
+				a.ui.error(err)
+			}
+		})
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'm',
+				display: "m",
+			},
+			action:      "mark",
+			description: "Toggle the mark of the item",
+			handler:     m.toggleMark,
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'M',
+				display: "M",
+			},
+			action:      "mark-all",
+			description: "Mark all the items",
+			handler:     m.markAll,
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'I',
+				display: "I",
+			},
+			action:      "invert-marks",
+			description: "Invert the marks",
+			handler:     m.invertMarks,
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'X',
+				display: "X",
+			},
+			action:      "bulk-kill",
+			description: "Kill the marked items",
+			handler: bulk(func(items []*target) {
+				run("Kill the marked items ?", a.killPlan(items))
+			}),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'd',
+				display: "d",
+			},
+			action:      "bulk-detach",
+			description: "Detach the clients of the marked sessions",
+			handler: bulk(func(items []*target) {
+				run("Detach the marked sessions ?", a.detachPlan(items))
+			}),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'v',
+				display: "v",
+			},
+			action:      "bulk-move",
+			description: "Move the marked windows to a session",
+			handler: bulk(func(items []*target) {
+				a.pickSession("Move the marked windows to", nil, func(session *gotmux.Session) {
+					run("Move the marked windows ?", a.movePlan(items, session))
+				})
+			}),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'n',
+				display: "n",
+			},
+			action:      "bulk-rename",
+			description: "Rename the marked sessions and windows with a pattern ({name}, {n})",
+			handler: bulk(func(items []*target) {
+				a.ui.editor("Rename pattern, i.e. {name}-old or dev-{n}", "{name}", func(pattern string) {
+					run("Rename the marked items ?", a.renamePlan(items, pattern))
+				})
+			}),
+		},
+	}
+}
+
+// Step of a bulk action, described before running it.
+type bulkStep struct {
+	text string
+	run  func() error
+}
+
+// Describes the item for the plans, i.e. window dev:1 (editor).
+func describe(item *target) string {
+	switch {
+	case item.pane != nil:
+		return fmt.Sprintf("pane %s:%d.%d (%s)", item.session.Name, item.window.Index, item.pane.Index, item.pane.CurrentCommand)
+	case item.window != nil:
+		return fmt.Sprintf("window %s:%d (%s)", item.session.Name, item.window.Index, item.window.Name)
+	default:
+		return "session " + item.session.Name
+	}
+}
+
+// Plans killing the items, skipping the ones killed along with a marked parent.
+func (a *App) killPlan(items []*target) []*bulkStep {
+	killed := make(map[string]bool)
+	plan := make([]*bulkStep, 0)
+	for _, item := range items {
+		if killed[item.session.Id] || item.window != nil && killed[item.window.Id] {
+			continue
+		}
+
+		step := &bulkStep{text: "kill " + describe(item)}
+		switch {
+		case item.pane != nil:
+			pane := item.pane
+			step.run = func() error { return a.backend.KillPane(pane) }
+		case item.window != nil:
+			window := item.window
+			killed[window.Id] = true
+			step.run = func() error { return a.backend.KillWindow(window) }
+		default:
+			session := item.session
+			killed[session.Id] = true
+			step.run = func() error { return a.backend.KillSession(session) }
+		}
+		plan = append(plan, step)
+	}
+	return plan
+}
+
+// Plans detaching the clients of the marked sessions.
+func (a *App) detachPlan(items []*target) []*bulkStep {
+	plan := make([]*bulkStep, 0)
+	for _, item := range items {
+		if item.window != nil {
+			continue
+		}
+
+		session := item.session
+		plan = append(plan, &bulkStep{
+			text: "detach the clients of " + describe(item),
+			run:  func() error { return a.backend.DetachSession(session) },
+		})
+	}
+	return plan
+}
+
+// Plans moving the marked windows to the session.
+func (a *App) movePlan(items []*target, session *gotmux.Session) []*bulkStep {
+	plan := make([]*bulkStep, 0)
+	for _, item := range items {
+		if item.window == nil || item.pane != nil || linkedTo(item.window)(session) {
+			continue
+		}
+
+		window, from := item.window, item.session
+		plan = append(plan, &bulkStep{
+			text: "move " + describe(item) + " to " + session.Name,
+			run:  func() error { return a.backend.MoveWindow(window, from, session) },
+		})
+	}
+	return plan
+}
+
+// Plans renaming the marked sessions and windows with the pattern,
+// where {name} is the current name and {n} the position of the item among the renamed ones.
+func (a *App) renamePlan(items []*target, pattern string) []*bulkStep {
+	plan := make([]*bulkStep, 0)
+	for _, item := range items {
+		if item.pane != nil {
+			continue
+		}
+
+		name := item.session.Name
+		if item.window != nil {
+			name = item.window.Name
+		}
+		n := strconv.Itoa(len(plan) + 1)
+		newName := strings.NewReplacer("{name}", name, "{n}", n).Replace(pattern)
+
+		step := &bulkStep{text: "rename " + describe(item) + " to " + newName}
+		if item.window != nil {
+			window := item.window
+			step.run = func() error { return a.backend.RenameWindow(window, newName) }
+		} else {
+			session := item.session
+			step.run = func() error { return a.backend.RenameSession(session, newName) }
+		}
+		plan = append(plan, step)
+	}
+	return plan
+}
+
+// Opens a picker over the sessions, calling done with the chosen one.
+// The sessions for which skip returns true are not listed, skip may be nil.
+func (a *App) pickSession(title string, skip func(session *gotmux.Session) bool, done func(session *gotmux.Session)) {
+	sessions, err := a.backend.ListSessions()
+	if err != nil {
+		a.ui.error(err)
+		return
+	}
+	if skip != nil {
+		sessions = slices.DeleteFunc(sessions, skip)
+	}
+	if len(sessions) == 0 {
+		a.ui.message(title, "There is no other session")
+		return
+	}
+
+	candidates := make([]string, len(sessions))
+	for idx, session := range sessions {
+		candidates[idx] = session.Name
+	}
+	a.ui.finder(title, nil, candidates, nil, func(idx int, _ tcell.Key) {
+		done(sessions[idx])
+	})
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Marks the node of the item in the tree.
+func markNode(a *App, sessionId string, windowId string, paneId string) {
+	a.tree.SetCurrentNode(a.tree.reveal(sessionId, windowId, paneId))
+	press(a, tcell.KeyRune, 'm')
+}
+
+func TestDocumentedConfigHasNoKeyErrors(t *testing.T) {
+	readme, err := os.ReadFile(filepath.Join("..", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the examples of the readme, together in a single config
+	blocks := regexp.MustCompile("(?s)```toml\n(.*?)```").FindAllStringSubmatch(string(readme), -1)
+	examples := make([]string, 0)
+	for _, b := range blocks {
+		examples = append(examples, b[1])
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(strings.Join(examples, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, errs := loadConfig(path)
+	if len(errs) > 0 {
+		t.Fatalf("config errors: %v", errs)
+	}
+	if len(c.keys) == 0 || len(c.Commands) == 0 {
+		t.Fatal("the readme has no keys or commands examples")
+	}
+
+	conf = c
+	a := newApp(newFakeBackend())
+	a.initUI()
+	if errs := a.keyErrors(); len(errs) > 0 {
+		t.Fatalf("key errors: %v", errs)
+	}
+}
+
+func TestBulkKill(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta", "gamma")
+	w, err := f.NewWindow(sessions[1], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	// the window of the marked session is killed along with it
+	markNode(a, sessions[0].Id, "", "")
+	markNode(a, sessions[0].Id, "@0", "")
+	markNode(a, sessions[1].Id, w.Id, "")
+	press(a, tcell.KeyRune, 'X')
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	left, err := f.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, s := range left {
+		names = append(names, s.Name)
+	}
+	if !slices.Equal(names, []string{"beta", "gamma"}) {
+		t.Fatalf("sessions left = %v, want [beta gamma]", names)
+	}
+	if windows, _ := f.ListWindows(sessions[1]); len(windows) != 1 {
+		t.Fatalf("windows of beta = %d, want 1", len(windows))
+	}
+	if got := a.tree.markedTargets(); len(got) != 0 {
+		t.Fatalf("marks = %d, want cleared", len(got))
+	}
+}
+
+func TestBulkDetach(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	// windows have no clients of their own
+	markNode(a, sessions[1].Id, "", "")
+	markNode(a, sessions[0].Id, "@0", "")
+	press(a, tcell.KeyRune, 'd')
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	if !slices.Equal(f.detached, []string{"beta"}) {
+		t.Fatalf("detached = %v, want [beta]", f.detached)
+	}
+}
+
+func TestBulkWithoutMarks(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	press(a, tcell.KeyRune, 'X')
+	if !a.ui.root.HasPage(modalName) {
+		t.Fatal("expected a message about the missing marks")
+	}
+	press(a, tcell.KeyEnter, 0)
+
+	if sessions, _ := f.ListSessions(); len(sessions) != 1 {
+		t.Fatal("nothing should be killed")
+	}
+}
+
+func TestRenamePlan(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	windows, err := f.ListWindows(sessions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := a.renamePlan([]*target{
+		{session: sessions[0]},
+		{session: sessions[1], window: windows[0]},
+	}, "{name}-{n}")
+
+	texts := make([]string, 0)
+	for _, step := range plan {
+		texts = append(texts, step.text)
+		if err := step.run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"rename session alpha to alpha-1", "rename window beta:0 (bash) to bash-2"}
+	if !slices.Equal(texts, want) {
+		t.Fatalf("plan = %v, want %v", texts, want)
+	}
+	if w, _ := f.GetWindow(windows[0].Id); w.Name != "bash-2" {
+		t.Fatalf("window name = %q, want bash-2", w.Name)
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Keybindings reordering the windows of a session and the panes of a window, shared by the views.
+// The swaps act on the pane if one is selected, otherwise on the window.
+func (a *App) orderKeybindings(selected func() *target) []*Keybinding {
+	// swaps the selected item with the previous or next one, then refreshes the views
+	swap := func(offset int) func() {
+		return func() {
+			item := selected()
+			if item == nil || item.session == nil || item.window == nil {
+				return
+			}
+
+			var err error
+			if item.pane != nil {
+				err = a.swapPane(item.window, item.pane, offset)
+			} else {
+				err = a.swapWindow(item.session, item.window, offset)
+			}
+			if err != nil {
+				a.ui.error(err)
+			}
+			a.refresh()
+		}
+	}
+
+	// rotates the panes of the selected window, then refreshes the views
+	rotate := func(reverse bool) func() {
+		return func() {
+			item := selected()
+			if item == nil || item.window == nil {
+				return
+			}
+
+			if err := a.backend.RotatePanes(item.window, reverse); err != nil {
+				a.ui.error(err)
+			}
+			a.refresh()
+		}
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '{',
+				display: "{",
+			},
+			action:      "swap-up",
+			description: "Swap the window or pane with the previous one",
+			handler:     swap(-1),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '}',
+				display: "}",
+			},
+			action:      "swap-down",
+			description: "Swap the window or pane with the next one",
+			handler:     swap(1),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'o',
+				display: "o",
+			},
+			action:      "rotate-panes",
+			description: "Rotate the panes of the window",
+			handler:     rotate(false),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'O',
+				display: "O",
+			},
+			action:      "rotate-panes-back",
+			description: "Rotate the panes of the window backwards",
+			handler:     rotate(true),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'N',
+				display: "N",
+			},
+			action:      "renumber-windows",
+			description: "Renumber the windows of the session in order",
+			handler: func() {
+				item := selected()
+				if item == nil || item.session == nil {
+					return
+				}
+
+				if err := a.backend.RenumberWindows(item.session); err != nil {
+					a.ui.error(err)
+				}
+				a.refresh()
+			},
+		},
+	}
+}
+
+// Swaps the window with the one at the offset in the index order of the session.
+// Nothing is done at the ends.
+func (a *App) swapWindow(session *gotmux.Session, window *gotmux.Window, offset int) error {
+	windows, err := a.backend.ListWindows(session)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(windows, func(w *gotmux.Window) bool { return w.Id == window.Id })
+	if idx < 0 || idx+offset < 0 || idx+offset >= len(windows) {
+		return nil
+	}
+
+	return a.backend.SwapWindows(session, window, windows[idx+offset])
+}
+
+// Swaps the pane with the one at the offset in the index order of the window.
+// Nothing is done at the ends.
+func (a *App) swapPane(window *gotmux.Window, pane *gotmux.Pane, offset int) error {
+	panes, err := a.backend.ListPanes(window)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(panes, func(p *gotmux.Pane) bool { return p.Id == pane.Id })
+	if idx < 0 || idx+offset < 0 || idx+offset >= len(panes) {
+		return nil
+	}
+
+	return a.backend.SwapPanes(pane, panes[idx+offset])
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Gets the ids of the panes of the window in the fake backend, in index order.
+func paneIds(f *fakeBackend, windowId string) []string {
+	ids := make([]string, 0)
+	for _, p := range f.panes[windowId] {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
+
+func TestSwapWindows(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	if _, err := f.NewWindow(sessions[0], &gotmux.NewWindowOptions{WindowName: "logs"}); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", ""))
+	press(a, tcell.KeyRune, '}')
+	assertNoModal(t, a)
+	if names := windowNames(t, f, sessions[0].Id); !slices.Equal(names, []string{"logs", "bash"}) {
+		t.Fatalf("windows = %v, want [logs bash]", names)
+	}
+
+	// the last window can't go further
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", ""))
+	press(a, tcell.KeyRune, '}')
+	if names := windowNames(t, f, sessions[0].Id); !slices.Equal(names, []string{"logs", "bash"}) {
+		t.Fatalf("windows = %v, want [logs bash]", names)
+	}
+}
+
+func TestSwapAndRotatePanes(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	for range 2 {
+		if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", "%1"))
+	press(a, tcell.KeyRune, '{')
+	assertNoModal(t, a)
+	if ids := paneIds(f, "@0"); !slices.Equal(ids, []string{"%1", "%0", "%2"}) {
+		t.Fatalf("panes = %v, want [%%1 %%0 %%2]", ids)
+	}
+
+	press(a, tcell.KeyRune, 'o')
+	if ids := paneIds(f, "@0"); !slices.Equal(ids, []string{"%2", "%1", "%0"}) {
+		t.Fatalf("panes = %v, want [%%2 %%1 %%0]", ids)
+	}
+	press(a, tcell.KeyRune, 'O')
+	if ids := paneIds(f, "@0"); !slices.Equal(ids, []string{"%1", "%0", "%2"}) {
+		t.Fatalf("panes = %v, want [%%1 %%0 %%2]", ids)
+	}
+}
+
+func TestRenumberWindows(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	windows := make([]*gotmux.Window, 0)
+	for range 2 {
+		w, err := f.NewWindow(sessions[0], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		windows = append(windows, w)
+	}
+	if err := f.KillWindow(windows[0]); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "", ""))
+	press(a, tcell.KeyRune, 'N')
+	assertNoModal(t, a)
+
+	indexes := make([]int, 0)
+	for _, w := range f.windows[sessions[0].Id] {
+		indexes = append(indexes, w.Index)
+	}
+	if !slices.Equal(indexes, []int{0, 1}) {
+		t.Fatalf("indexes = %v, want [0 1]", indexes)
+	}
+	if n := unwrapNode(a.tree.reveal(sessions[0].Id, windows[1].Id, "")); n.window().Index != 1 {
+		t.Fatalf("tree index = %d, want 1", n.window().Index)
+	}
+}
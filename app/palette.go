@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Keybindings opening the command palette, shared by the views.
+func (a *App) paletteKeybindings() []*Keybinding {
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    ':',
+				display: ":",
+			},
+			action:      "palette",
+			description: "Open the command palette",
+			handler:     a.palette,
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyCtrlP,
+				display: "Ctrl-P",
+			},
+			action:      "palette",
+			description: "Open the command palette",
+			handler:     a.palette,
+		},
+	}
+}
+
+// Action listed in the command palette.
+type paletteItem struct {
+	binding *Keybinding
+
+	// view the action belongs to, switched to before running it if it is not focused
+	view *viewBindings
+}
+
+// Opens the command palette over the actions of all the views and the custom commands.
+func (a *App) palette() {
+	focused := a.focusedBindings()
+	items, candidates := a.paletteItems(focused)
+	a.ui.finder("Command palette", nil, candidates, nil, func(idx int, _ tcell.Key) {
+		item := items[idx]
+		if item.view != focused {
+			a.tabs.SwitchToPage(item.view.page)
+			a.ui.SetFocus(item.view.view)
+		}
+		item.binding.handler()
+	})
+}
+
+// Lists the actions of the palette along with their texts.
+// The actions of the focused view come first and run against its selection,
+// the actions of the other views follow, labelled with the view and run in it.
+func (a *App) paletteItems(focused *viewBindings) ([]*paletteItem, []string) {
+	views := slices.Clone(a.bindings)
+	if focused != nil {
+		views = slices.DeleteFunc(views, func(v *viewBindings) bool { return v == focused })
+		views = slices.Insert(views, 0, focused)
+	}
+
+	items := make([]*paletteItem, 0)
+	candidates := make([]string, 0)
+	for _, v := range views {
+		// views outside of the tabs, like the preview, only act while focused
+		if v.page == "" && v != focused {
+			continue
+		}
+
+		// an action may have several keys in a view, it is listed once per view
+		seen := make(map[string]bool)
+		for _, binding := range v.keys {
+			if binding.handler == nil || binding.action == "palette" || seen[binding.action] {
+				continue
+			}
+			seen[binding.action] = true
+
+			text := fmt.Sprintf("%-20s %-40s %s", binding.action, binding.description, binding.key.display)
+			if v != focused {
+				text += "  (" + v.name + ")"
+			}
+			items = append(items, &paletteItem{binding, v})
+			candidates = append(candidates, text)
+		}
+	}
+
+	return items, candidates
+}
+
+// Gets the keybindings of the focused view, nil if the focus is elsewhere.
+func (a *App) focusedBindings() *viewBindings {
+	focus := a.ui.GetFocus()
+	for _, v := range a.bindings {
+		if v.view == focus {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+// Gets the names of the views listing the action in the palette, in order.
+func paletteViews(items []*paletteItem, action string) []string {
+	views := make([]string, 0)
+	for _, item := range items {
+		if item.binding.action == action {
+			views = append(views, item.view.name)
+		}
+	}
+	return views
+}
+
+func TestPaletteItems(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	focused := a.focusedBindings()
+	if focused == nil || focused.name != "tree" {
+		t.Fatal("tree not focused")
+	}
+	items, candidates := a.paletteItems(focused)
+	if len(items) != len(candidates) {
+		t.Fatalf("%d items for %d candidates", len(items), len(candidates))
+	}
+
+	// the same action of every view is listed, the focused view first
+	views := paletteViews(items, "kill")
+	if len(views) != 4 || views[0] != "tree" {
+		t.Fatalf("kill listed in %v, want the tree then the 3 tables", views)
+	}
+
+	// the palette itself and the views outside of the tabs are not listed
+	if views := paletteViews(items, "palette"); len(views) != 0 {
+		t.Fatalf("palette listed in %v", views)
+	}
+	if views := paletteViews(items, "close-history"); len(views) != 0 {
+		t.Fatalf("close-history listed in %v", views)
+	}
+
+	// actions of the other views are labelled with their view
+	for idx, item := range items {
+		if item.view != focused && !strings.HasSuffix(candidates[idx], "("+item.view.name+")") {
+			t.Fatalf("candidate %q not labelled with %s", candidates[idx], item.view.name)
+		}
+	}
+}
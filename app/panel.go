@@ -2,8 +2,8 @@ package app
 
 import (
 	"fmt"
-	"log"
 	"strconv"
+	"strings"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/gdamore/tcell/v2"
@@ -13,6 +13,9 @@ import (
 type Panel struct {
 	*tview.Flex
 
+	// backend to query tmux
+	backend Backend
+
 	// panel tables
 	sessions *Table[gotmux.Session]
 	windows  *Table[gotmux.Window]
@@ -22,6 +25,7 @@ type Panel struct {
 // Inits the panel (sessions, windows and panes table).
 func (a *App) initPanel() {
 	p := &Panel{}
+	p.backend = a.backend
 
 	// inist the views in the panel
 	p.initSessionsView(a)
@@ -29,8 +33,7 @@ func (a *App) initPanel() {
 	p.initPanesView(a)
 
 	// sync the data and update the preview
-	pane := p.sync()
-	a.preview.update(pane)
+	p.refresh(a)
 
 	// build and assemble panel with views
 	p.Flex = tview.NewFlex()
@@ -46,19 +49,29 @@ func (p *Panel) initSessionsView(a *App) {
 	colTitles := []string{"Name", "Last Attached", "Created"}
 	t := newTable("Sessions", colTitles, func(s *gotmux.Session) {
 		// sync windows down
-		pane := p.syncWindowsDown(s)
+		pane, err := p.syncWindowsDown(s)
+		if err != nil {
+			a.ui.error(err)
+		}
 
 		// update the preview
 		a.preview.update(pane)
 	})
+	t.key = func(s *gotmux.Session) string {
+		return s.Id
+	}
 
 	// when session is selected
 	t.SetSelectedFunc(func(row, _ int) {
 		// suspend the ui and attach the session
 		s := p.sessions.getSelected()
-		a.ui.Suspend(func() {
-			s.Attach()
-		})
+		if s == nil {
+			return
+		}
+
+		if err := a.attach(s); err != nil {
+			a.ui.error(err)
+		}
 	})
 
 	// defing keybindings
@@ -70,11 +83,13 @@ func (p *Panel) initSessionsView(a *App) {
 				rune:    '?',
 				display: "?",
 			},
+			action:      "help",
 			description: "Toggle cheatsheet",
 			handler: func() {
 				a.ui.help(kh)
 			},
 		},
+		a.finderKeybinding(),
 		{
 			key: &Key{
 				display: "Enter",
@@ -87,14 +102,20 @@ func (p *Panel) initSessionsView(a *App) {
 				rune:    'D',
 				display: "D",
 			},
+			action:      "kill",
 			description: "Kill session",
 			handler: func() {
+				session := t.getSelected()
+				if session == nil {
+					return
+				}
+
 				a.ui.confirm("Are you sure you want to kill this session", func(b bool) {
 					if b {
-						session := t.getSelected()
-						session.Kill()
-						pane := p.sync()
-						a.preview.update(pane)
+						if err := a.backend.KillSession(session); err != nil {
+							a.ui.error(err)
+						}
+						p.refresh(a)
 					}
 				})
 			},
@@ -105,13 +126,19 @@ func (p *Panel) initSessionsView(a *App) {
 				rune:    'r',
 				display: "r",
 			},
+			action:      "rename",
 			description: "Rename session",
 			handler: func() {
 				session := t.getSelected()
+				if session == nil {
+					return
+				}
+
 				a.ui.editor("New session name", session.Name, func(s string) {
-					session.Rename(s)
-					pane := p.sync()
-					a.preview.update(pane)
+					if err := a.backend.RenameSession(session, s); err != nil {
+						a.ui.error(err)
+					}
+					p.refresh(a)
 				})
 			},
 		},
@@ -121,29 +148,21 @@ func (p *Panel) initSessionsView(a *App) {
 				rune:    'a',
 				display: "a",
 			},
+			action:      "new-session",
 			description: "Create new session",
 			handler: func() {
-				tmux, _ := gotmux.DefaultTmux()
-				a.ui.editor("New session name", "", func(s string) {
-					session, _ := tmux.NewSession(&gotmux.SessionOptions{
-						Name: s,
-					})
-
-					a.ui.Suspend(func() {
-						session.Attach()
-					})
-
-					pane := p.sync()
-					a.preview.update(pane)
-				})
+				a.newSession()
 			},
 		},
+		a.templateKeybinding(),
+		a.exportKeybinding(t.getSelected),
 		{
 			key: &Key{
 				key:     tcell.KeyRune,
 				display: "space",
 				rune:    ' ',
 			},
+			action:      "focus-next",
 			description: "Focus windows",
 			handler: func() {
 				a.ui.SetFocus(p.windows)
@@ -157,6 +176,32 @@ func (p *Panel) initSessionsView(a *App) {
 		},
 	})
 
+	// saving and restoring the sessions
+	kh = append(kh, a.stateKeybindings()...)
+
+	// custom commands of the config
+	kh = append(kh, a.commandKeybindings(func() *target {
+		// the active window and pane of the session, rather than the ones selected below
+		if session := t.getSelected(); session != nil {
+			return &target{session: session}
+		}
+		return nil
+	})...)
+
+	// history of the previewed pane, and typing in it live
+	kh = append(kh, a.historyKeybinding(), a.liveKeybinding())
+
+	// marks and bulk actions
+	kh = append(kh, a.markKeybindings(&tableMarker[gotmux.Session]{t, func(s *gotmux.Session) *target {
+		return &target{session: s}
+	}})...)
+
+	// command palette
+	kh = append(kh, a.paletteKeybindings()...)
+
+	// apply the keys of the config
+	handle := a.bind("panel", "sessions", t, kh)
+
 	// set key bindings
 	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		row, _ := t.GetSelection()
@@ -165,7 +210,9 @@ func (p *Panel) initSessionsView(a *App) {
 			return event
 		}
 
-		kh.handle(event)
+		if handle(event) {
+			return nil
+		}
 		return event
 	})
 
@@ -174,17 +221,22 @@ func (p *Panel) initSessionsView(a *App) {
 
 func (p *Panel) initWindows(a *App) {
 	// create table
-	colTitles := []string{"ID", "Index", "Name", "Activity", "Active", "# Clients", "Size", "Cell Size"}
+	colTitles := []string{"ID", "Index", "Name", "Activity", "Active", "Linked", "# Clients", "Size", "Cell Size"}
 	t := newTable("Windows", colTitles, func(w *gotmux.Window) {
-		pane := p.syncPanesDown(w)
+		pane, err := p.syncPanesDown(w)
+		if err != nil {
+			a.ui.error(err)
+		}
 		a.preview.update(pane)
 	})
+	t.key = func(w *gotmux.Window) string {
+		return w.Id
+	}
 
 	t.SetSelectedFunc(func(row, column int) {
-		s := p.sessions.getSelected()
-		a.ui.Suspend(func() {
-			s.Attach()
-		})
+		if err := a.attachPane(p.sessions.getSelected(), t.getSelected(), nil); err != nil {
+			a.ui.error(err)
+		}
 	})
 
 	// defing keybindings
@@ -194,12 +246,7 @@ func (p *Panel) initWindows(a *App) {
 			key: &Key{
 				display: "Enter",
 			},
-			description: "Attach to session",
-			handler: func() {
-				// get selected session and attach
-				session := p.sessions.getSelected()
-				session.Attach()
-			},
+			description: "Attach to window",
 		},
 		{
 			key: &Key{
@@ -207,25 +254,33 @@ func (p *Panel) initWindows(a *App) {
 				rune:    '?',
 				display: "?",
 			},
+			action:      "help",
 			description: "Toggle cheatsheet",
 			handler: func() {
 				a.ui.help(kh)
 			},
 		},
+		a.finderKeybinding(),
 		{
 			key: &Key{
 				key:     tcell.KeyRune,
 				rune:    'r',
 				display: "r",
 			},
+			action:      "rename",
 			description: "Rename window",
 			handler: func() {
 				cur := p.windows.getSelected()
+				if cur == nil {
+					return
+				}
+
 				a.ui.editor("New window name", cur.Name, func(s string) {
-					cur.Rename(s)
+					if err := a.backend.RenameWindow(cur, s); err != nil {
+						a.ui.error(err)
+					}
+					p.refresh(a)
 				})
-				pane := p.sync()
-				a.preview.update(pane)
 			},
 		},
 		{
@@ -234,17 +289,23 @@ func (p *Panel) initWindows(a *App) {
 				rune:    'D',
 				display: "D",
 			},
+			action:      "kill",
 			description: "Kill window",
 			handler: func() {
 				cur := p.windows.getSelected()
+				if cur == nil {
+					return
+				}
+
 				a.ui.confirm("Are you sure you want to kill this window?", func(b bool) {
 					if !b {
 						return
 					}
 
-					cur.Kill()
-					pane := p.sync()
-					a.preview.update(pane)
+					if err := a.backend.KillWindow(cur); err != nil {
+						a.ui.error(err)
+					}
+					p.refresh(a)
 				})
 			},
 		},
@@ -253,6 +314,7 @@ func (p *Panel) initWindows(a *App) {
 				key:     tcell.KeyEsc,
 				display: "esc",
 			},
+			action:      "back",
 			description: "Go back",
 			handler: func() {
 				a.ui.SetFocus(p.sessions)
@@ -264,6 +326,7 @@ func (p *Panel) initWindows(a *App) {
 				display: "space",
 				rune:    ' ',
 			},
+			action:      "focus-next",
 			description: "Focus panes",
 			handler: func() {
 				a.ui.SetFocus(p.panes)
@@ -271,6 +334,48 @@ func (p *Panel) initWindows(a *App) {
 		},
 	})
 
+	// window and pane creation
+	kh = append(kh, a.createKeybindings(func() *target {
+		return p.selected(false)
+	})...)
+
+	// custom commands of the config
+	kh = append(kh, a.commandKeybindings(func() *target {
+		return p.selected(false)
+	})...)
+
+	// moving and linking windows between sessions
+	kh = append(kh, a.linkKeybindings(func() *target {
+		return p.selected(false)
+	})...)
+
+	// reordering windows and panes
+	kh = append(kh, a.orderKeybindings(func() *target {
+		return p.selected(false)
+	})...)
+
+	// layout of the window
+	kh = append(kh, a.layoutKeybinding(func() *target {
+		return p.selected(false)
+	}))
+
+	// history of the previewed pane, and typing in it live
+	kh = append(kh, a.historyKeybinding(), a.liveKeybinding())
+
+	// marks and bulk actions
+	kh = append(kh, a.markKeybindings(&tableMarker[gotmux.Window]{t, func(w *gotmux.Window) *target {
+		if session := p.sessions.getSelected(); session != nil {
+			return &target{session: session, window: w}
+		}
+		return nil
+	}})...)
+
+	// command palette
+	kh = append(kh, a.paletteKeybindings()...)
+
+	// apply the keys of the config
+	handle := a.bind("panel", "windows", t, kh)
+
 	// set key bindings
 	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		row, _ := t.GetSelection()
@@ -284,7 +389,9 @@ func (p *Panel) initWindows(a *App) {
 			a.ui.SetFocus(p.panes)
 		}
 
-		kh.handle(event)
+		if handle(event) {
+			return nil
+		}
 		return event
 	})
 
@@ -297,6 +404,9 @@ func (p *Panel) initPanesView(a *App) {
 	t := newTable("Panes", colTitles, func(p *gotmux.Pane) {
 		a.preview.update(p)
 	})
+	t.key = func(p *gotmux.Pane) string {
+		return p.Id
+	}
 
 	var kh KeybdindingHolder
 	kh = KeybdindingHolder([]*Keybinding{
@@ -304,7 +414,7 @@ func (p *Panel) initPanesView(a *App) {
 			key: &Key{
 				display: "Enter",
 			},
-			description: "Attach to session",
+			description: "Attach to pane",
 		},
 		{
 			key: &Key{
@@ -312,15 +422,23 @@ func (p *Panel) initPanesView(a *App) {
 				display: "D",
 				rune:    'D',
 			},
+			action:      "kill",
 			description: "Kill pane",
 			handler: func() {
+				pane := t.getSelected()
+				if pane == nil {
+					return
+				}
+
 				a.ui.confirm("Are you sure you want to kill this pane?", func(b bool) {
 					if !b {
 						return
 					}
 
-					t.getSelected().Kill()
-					p.sync()
+					if err := a.backend.KillPane(pane); err != nil {
+						a.ui.error(err)
+					}
+					p.refresh(a)
 				})
 			},
 		},
@@ -330,16 +448,19 @@ func (p *Panel) initPanesView(a *App) {
 				rune:    '?',
 				display: "?",
 			},
+			action:      "help",
 			description: "Toggle cheatsheet",
 			handler: func() {
 				a.ui.help(kh)
 			},
 		},
+		a.finderKeybinding(),
 		{
 			key: &Key{
 				key:     tcell.KeyEsc,
 				display: "esc",
 			},
+			action:      "back",
 			description: "Go back",
 			handler: func() {
 				a.ui.SetFocus(p.windows)
@@ -347,14 +468,58 @@ func (p *Panel) initPanesView(a *App) {
 		},
 	})
 
-	t.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			a.ui.Suspend(func() {
-				p.sessions.getSelected().Attach()
-			})
+	// window and pane creation
+	kh = append(kh, a.createKeybindings(func() *target {
+		return p.selected(true)
+	})...)
+
+	// custom commands of the config
+	kh = append(kh, a.commandKeybindings(func() *target {
+		return p.selected(true)
+	})...)
+
+	// typing in the panes
+	kh = append(kh, a.sendKeybindings(func() *target {
+		return p.selected(true)
+	})...)
+
+	// reordering windows and panes
+	kh = append(kh, a.orderKeybindings(func() *target {
+		return p.selected(true)
+	})...)
+
+	// layouts and sizes of the window and panes
+	kh = append(kh, a.layoutKeybinding(func() *target {
+		return p.selected(true)
+	}))
+	kh = append(kh, a.paneLayoutKeybindings(func() *target {
+		return p.selected(true)
+	})...)
+
+	// history of the previewed pane, and typing in it live
+	kh = append(kh, a.historyKeybinding(), a.liveKeybinding())
+
+	// marks and bulk actions
+	kh = append(kh, a.markKeybindings(&tableMarker[gotmux.Pane]{t, func(pane *gotmux.Pane) *target {
+		session, window := p.sessions.getSelected(), p.windows.getSelected()
+		if session == nil || window == nil {
+			return nil
+		}
+		return &target{session: session, window: window, pane: pane}
+	}})...)
+
+	t.SetSelectedFunc(func(row, column int) {
+		if err := a.attachPane(p.sessions.getSelected(), p.windows.getSelected(), t.getSelected()); err != nil {
+			a.ui.error(err)
 		}
 	})
 
+	// command palette
+	kh = append(kh, a.paletteKeybindings()...)
+
+	// apply the keys of the config
+	handle := a.bind("panel", "panes", t, kh)
+
 	// set key bindings
 	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		row, _ := t.GetSelection()
@@ -365,23 +530,47 @@ func (p *Panel) initPanesView(a *App) {
 			a.ui.SetFocus(p.windows)
 		}
 
-		kh.handle(event)
+		if handle(event) {
+			return nil
+		}
 		return event
 	})
 
 	p.panes = t
 }
 
-// Syncs the entire panel with tmux.
-// Returns the selected pane
-func (p *Panel) sync() *gotmux.Pane {
-	tmux, err := gotmux.DefaultTmux()
+// Syncs the panel and updates the preview, reporting errors to the user.
+func (p *Panel) refresh(a *App) {
+	pane, err := p.sync()
 	if err != nil {
-		log.Panic(err)
+		a.ui.error(err)
 	}
+	a.preview.update(pane)
+}
 
+// Gets the selected session and window, and the selected pane if withPane is set.
+// Returns nil if there is no session.
+func (p *Panel) selected(withPane bool) *target {
+	session := p.sessions.getSelected()
+	if session == nil {
+		return nil
+	}
+
+	item := &target{session: session, window: p.windows.getSelected()}
+	if withPane {
+		item.pane = p.panes.getSelected()
+	}
+	return item
+}
+
+// Syncs the entire panel with tmux.
+// Returns the selected pane
+func (p *Panel) sync() (*gotmux.Pane, error) {
 	// sync sessions
-	sessions, _ := tmux.ListSessions()
+	sessions, err := p.backend.ListSessions()
+	if err != nil {
+		return nil, err
+	}
 	p.syncSessions(sessions)
 
 	// if there is a selected session, sync the windows for it
@@ -390,26 +579,35 @@ func (p *Panel) sync() *gotmux.Pane {
 		return p.syncWindowsDown(session)
 	}
 
-	return nil
+	// no sessions, clear the tables below
+	p.syncWindows(nil)
+	p.syncPanes(nil)
+	return nil, nil
 }
 
 // Syncs the panel from windows down.
-func (p *Panel) syncWindowsDown(session *gotmux.Session) *gotmux.Pane {
-	windows, _ := session.ListWindows()
+func (p *Panel) syncWindowsDown(session *gotmux.Session) (*gotmux.Pane, error) {
+	windows, err := p.backend.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
 	p.syncWindows(windows)
 	window := p.windows.getSelected()
 	if window != nil {
 		return p.syncPanesDown(window)
 	}
-	return nil
+	return nil, nil
 }
 
 // Syncs the panel from the panes down (and preview).
-func (p *Panel) syncPanesDown(window *gotmux.Window) *gotmux.Pane {
-	panes, _ := window.ListPanes()
+func (p *Panel) syncPanesDown(window *gotmux.Window) (*gotmux.Pane, error) {
+	panes, err := p.backend.ListPanes(window)
+	if err != nil {
+		return nil, err
+	}
 	p.syncPanes(panes)
 	pane := p.panes.getSelected()
-	return pane
+	return pane, nil
 }
 
 // Syncs sessions to the table
@@ -418,7 +616,7 @@ func (p *Panel) syncSessions(sessions []*gotmux.Session) {
 	p.sessions.setRows(sessions, func(s *gotmux.Session) []*tview.TableCell {
 		created := unixTime(s.Created).Format(timeFormat())
 		lastAttached := unixTime(s.LastAttached).Format(timeFormat())
-		name := trimStrBack(s.Name, stringLengthLimit)
+		name := trimStrBack(s.Name, conf.ColumnWidth)
 		return []*tview.TableCell{
 			tview.NewTableCell(name),
 			tview.NewTableCell(lastAttached),
@@ -441,12 +639,19 @@ func (p *Panel) syncWindows(windows []*gotmux.Window) {
 		idx := strconv.Itoa(w.Index)
 		dimensions := fmt.Sprintf("%d x %d", w.Width, w.Height)
 		cellDimensions := fmt.Sprintf("%d x %d", w.CellWidth, w.CellHeight)
+
+		// sessions sharing the window, if it is linked to several
+		linked := ""
+		if w.LinkedSessions > 1 {
+			linked = trimStr(strings.Join(w.LinkedSessionsList, ", "), conf.ColumnWidth)
+		}
 		return []*tview.TableCell{
 			tview.NewTableCell(w.Id),
 			tview.NewTableCell(idx),
 			tview.NewTableCell(w.Name),
 			tview.NewTableCell(activity),
 			tview.NewTableCell(active),
+			tview.NewTableCell(linked),
 			tview.NewTableCell(clients),
 			tview.NewTableCell(dimensions),
 			tview.NewTableCell(cellDimensions),
@@ -465,7 +670,7 @@ func (p *Panel) syncPanes(panes []*gotmux.Pane) {
 			active = "Yes"
 		}
 
-		path := trimStrBack(pane.CurrentPath, stringLengthLimit)
+		path := trimStrBack(pane.CurrentPath, conf.ColumnWidth)
 		return []*tview.TableCell{
 			tview.NewTableCell(pane.CurrentCommand),
 			tview.NewTableCell(pid),
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Gets the names of the sessions listed in the panel, in order.
+func panelSessions(a *App) []string {
+	names := make([]string, 0)
+	for _, s := range a.panel.sessions.values {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Shows the panel, focusing its sessions.
+func showPanel(t *testing.T, a *App) {
+	t.Helper()
+	press(a, tcell.KeyRight, 0)
+	if a.ui.GetFocus() != a.panel.sessions {
+		t.Fatal("panel sessions not focused")
+	}
+}
+
+func TestPanelBuild(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestApp(t, f)
+	assertNoModal(t, a)
+
+	if got := panelSessions(a); len(got) != 2 || got[0] != "alpha" || got[1] != "beta" {
+		t.Fatalf("sessions = %v, want [alpha beta]", got)
+	}
+	if s := a.panel.sessions.getSelected(); s.Id != sessions[0].Id {
+		t.Fatalf("selected session = %s, want %s", s.Id, sessions[0].Id)
+	}
+	if got := len(a.panel.windows.values); got != 1 {
+		t.Fatalf("windows = %d, want 1", got)
+	}
+	if got := len(a.panel.panes.values); got != 2 {
+		t.Fatalf("panes = %d, want 2", got)
+	}
+}
+
+func TestPanelSync(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	// select the second session, then change tmux outside of the app
+	a.panel.sessions.selectKey(sessions[1].Id)
+	if err := f.KillSession(sessions[0]); err != nil {
+		t.Fatal(err)
+	}
+	newTestSessions(t, f, "gamma")
+	if _, err := f.NewWindow(sessions[1], nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.panel.sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := panelSessions(a); len(got) != 2 || got[0] != "beta" || got[1] != "gamma" {
+		t.Fatalf("sessions = %v, want [beta gamma]", got)
+	}
+	if s := a.panel.sessions.getSelected(); s.Id != sessions[1].Id {
+		t.Fatalf("selected session = %s, want %s", s.Id, sessions[1].Id)
+	}
+	if got := len(a.panel.windows.values); got != 2 {
+		t.Fatalf("windows = %d, want 2", got)
+	}
+}
+
+func TestPanelKill(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+	showPanel(t, a)
+
+	press(a, tcell.KeyRune, 'D')
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	if got := panelSessions(a); len(got) != 1 || got[0] != "beta" {
+		t.Fatalf("sessions = %v, want [beta]", got)
+	}
+	left, err := f.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].Name != "beta" {
+		t.Fatalf("sessions left = %v, want [beta]", left)
+	}
+}
+
+func TestPanelRename(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+	showPanel(t, a)
+
+	press(a, tcell.KeyRune, 'r')
+	press(a, tcell.KeyCtrlU, 0)
+	typeText(a, "work")
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	if got := panelSessions(a); len(got) != 1 || got[0] != "work" {
+		t.Fatalf("sessions = %v, want [work]", got)
+	}
+	if a.ui.GetFocus() != a.panel.sessions {
+		t.Fatal("sessions not focused back")
+	}
+}
+
+func TestPanelError(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	f.err = errors.New("no server running")
+	if _, err := a.panel.sync(); err == nil {
+		t.Fatal("expected the sync to fail")
+	}
+}
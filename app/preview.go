@@ -2,39 +2,584 @@ package app
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+// Escape sequences of the captured content, removed to search the text.
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;:?]*[a-zA-Z]`)
+
 type Preview struct {
 	*tview.TextView
+
+	// backend to capture the panes
+	backend Backend
+
+	// pane currently previewed, the active one when a window is previewed
+	pane *gotmux.Pane
+
+	// window whose layout is drawn in place of the text, nil when a single pane is previewed
+	window *gotmux.Window
+
+	// panes of the window, with their captured content
+	layout []*layoutPane
+
+	// ids of the panes shown, read by the listener of the notifications outside of the event loop
+	shown atomic.Pointer[[]string]
+
+	// set while the history of the pane is read, the preview is then focused and not updated
+	history bool
+
+	// captured history, with its colors
+	content string
+
+	// searched text, its number of matches and the highlighted one
+	query   string
+	matches int
+	match   int
+
+	// set while the keys are forwarded to the pane, the preview is then focused and captures it continuously
+	live bool
+
+	// closed to stop capturing the live pane
+	stopLive chan struct{}
+
+	// keys leaving the live pane, shown in its title
+	leave *Key
+
+	// view focused before the history or the live pane was opened, focused again when it is closed
+	back tview.Primitive
 }
 
 func (a *App) initPreview() {
 	p := &Preview{}
 	p.TextView = tview.NewTextView()
+	p.backend = a.backend
 	p.SetBorder(true)
 	p.SetTitle(surroundSpace("Preview"))
 	p.SetDynamicColors(true)
 	p.SetBackgroundColor(tcell.ColorNone)
 	p.SetWrap(false)
 	a.preview = p
+
+	// the preview is only focused while reading the history or typing in the live pane
+	p.SetFocusFunc(func() {
+		p.SetBorderColor(conf.theme.border)
+		p.SetTitleColor(conf.theme.title)
+	})
+	p.SetBlurFunc(func() {
+		p.SetBorderColor(tview.Styles.BorderColor)
+		p.SetTitleColor(tview.Styles.TitleColor)
+	})
+
+	// keybindings of the history
+	var kh KeybdindingHolder
+	kh = KeybdindingHolder([]*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '?',
+				display: "?",
+			},
+			action:      "help",
+			description: "Toggle cheatsheet",
+			handler: func() {
+				a.ui.help(kh)
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    '/',
+				display: "/",
+			},
+			action:      "search-history",
+			description: "Search the history",
+			handler: func() {
+				a.ui.editor("Search", p.query, p.search)
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'n',
+				display: "n",
+			},
+			action:      "next-match",
+			description: "Go to the next match",
+			handler: func() {
+				p.jump(1)
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'N',
+				display: "N",
+			},
+			action:      "previous-match",
+			description: "Go to the previous match",
+			handler: func() {
+				p.jump(-1)
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'R',
+				display: "R",
+			},
+			action:      "refresh-history",
+			description: "Capture the history again",
+			handler: func() {
+				if err := p.captureHistory(); err != nil {
+					a.ui.error(err)
+				}
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyEsc,
+				display: "esc",
+			},
+			action:      "clear-search",
+			description: "Clear the search, or close the history",
+			handler: func() {
+				if p.query != "" {
+					p.search("")
+					return
+				}
+				a.closeHistory()
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'q',
+				display: "q",
+			},
+			action:      "close-history",
+			description: "Close the history",
+			handler:     a.closeHistory,
+		},
+		{
+			key: &Key{
+				display: "j/k, Up/Down",
+			},
+			description: "Scroll by a line",
+		},
+		{
+			key: &Key{
+				display: "PgUp/PgDn",
+			},
+			description: "Scroll by a page",
+		},
+		{
+			key: &Key{
+				display: "g/G",
+			},
+			description: "Scroll to the start/end",
+		},
+	})
+
+	// apply the keys of the config, the preview is not part of the tabs
+	handle := a.bind("", "preview", p, kh)
+
+	// register the keybindings
+	p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if handle(event) {
+			return nil
+		}
+		return event
+	})
+}
+
+// Pane drawn in the layout of the previewed window.
+type layoutPane struct {
+	pane *gotmux.Pane
+
+	// position and size of the pane in the window, in cells
+	left   int
+	top    int
+	width  int
+	height int
+
+	// captured lines, with their colors as tags
+	lines []string
 }
 
 func (p *Preview) update(pane *gotmux.Pane) {
-	if pane == nil {
+	// the live pane is kept even if the selection changes
+	if pane == nil || p.history || p.live && pane.Id != p.pane.Id {
 		return
 	}
+	p.set(pane, nil, nil)
 
 	// clear preview before
 	p.Clear()
 
 	// capture the contents of the current pane
-	content, _ := pane.Capture()
+	content, err := p.backend.CapturePane(pane)
+	if err != nil {
+		p.showError(err)
+		return
+	}
 
 	// write to the target view with a ansii writer
 	ansiiWriter := tview.ANSIWriter(p)
 	fmt.Fprintln(ansiiWriter, content)
 }
+
+// Previews the layout of the window, each pane being captured in its box.
+func (p *Preview) updateWindow(window *gotmux.Window) {
+	if window == nil || p.history || p.live {
+		return
+	}
+
+	panes, err := p.backend.ListPanes(window)
+	if err != nil {
+		p.showError(err)
+		return
+	}
+	if len(panes) == 0 {
+		return
+	}
+
+	layout := make([]*layoutPane, 0, len(panes))
+	active := panes[0]
+	for _, pane := range panes {
+		content, err := p.backend.CapturePane(pane)
+		if err != nil {
+			p.showError(err)
+			return
+		}
+
+		// the blank lines below the output are dropped, the last lines are shown if the box is too small
+		lines := strings.Split(strings.TrimRight(content, " \n"), "\n")
+		for idx, line := range lines {
+			lines[idx] = tview.TranslateANSI(line)
+		}
+
+		left, _ := strconv.Atoi(pane.Left)
+		top, _ := strconv.Atoi(pane.Top)
+		layout = append(layout, &layoutPane{pane, left, top, pane.Width, pane.Height, lines})
+		if pane.Active {
+			active = pane
+		}
+	}
+
+	p.set(active, window, layout)
+}
+
+// Previews the layout of the window if it is set, otherwise the pane.
+func (p *Preview) show(window *gotmux.Window, pane *gotmux.Pane) {
+	if window != nil {
+		p.updateWindow(window)
+		return
+	}
+	p.update(pane)
+}
+
+// Re-captures the previewed pane or window.
+func (p *Preview) refresh() {
+	p.show(p.window, p.pane)
+}
+
+// Sets the previewed pane, and the window whose layout is drawn if it is not nil.
+func (p *Preview) set(pane *gotmux.Pane, window *gotmux.Window, layout []*layoutPane) {
+	p.pane = pane
+	p.window = window
+	p.layout = layout
+
+	shown := make([]string, 0, len(layout))
+	if window != nil {
+		for _, lp := range layout {
+			shown = append(shown, lp.pane.Id)
+		}
+	} else if pane != nil {
+		shown = append(shown, pane.Id)
+	}
+	p.shown.Store(&shown)
+}
+
+// Checks if the pane is the one previewed, or one of the panes of the previewed window.
+// It can be called outside of the event loop.
+func (p *Preview) showing(paneId string) bool {
+	shown := p.shown.Load()
+	return shown != nil && slices.Contains(*shown, paneId)
+}
+
+// Shows the error in place of the content.
+func (p *Preview) showError(err error) {
+	logError(err)
+	p.set(p.pane, nil, nil)
+	p.Clear()
+	fmt.Fprintf(p, "[red]%s", tview.Escape(err.Error()))
+}
+
+// Draws the layout of the previewed window, or the text if a single pane is previewed.
+func (p *Preview) Draw(screen tcell.Screen) {
+	if p.window == nil {
+		p.TextView.Draw(screen)
+		return
+	}
+
+	p.DrawForSubclass(screen, p)
+	x, y, width, height := p.GetInnerRect()
+
+	// size of the window, the panes being separated by a border
+	cols, rows := 0, 0
+	for _, lp := range p.layout {
+		cols = max(cols, lp.left+lp.width)
+		rows = max(rows, lp.top+lp.height)
+	}
+	if cols == 0 || rows == 0 || width < 3 || height < 3 {
+		return
+	}
+
+	// the window is drawn with a border around it, scaled down if it does not fit
+	scale := func(v int, size int, avail int) int {
+		if size+2 <= avail {
+			return v
+		}
+		return v * (avail - 1) / (size + 1)
+	}
+
+	// connections of the border cells to their neighbors, to draw the junctions of the boxes
+	const (
+		up = 1 << iota
+		down
+		left
+		right
+	)
+	borders := make(map[[2]int]int)
+	highlighted := make(map[[2]int]bool)
+
+	type box struct {
+		*layoutPane
+		x0, y0, x1, y1 int
+	}
+	boxes := make([]*box, 0, len(p.layout))
+	for _, lp := range p.layout {
+		b := &box{
+			layoutPane: lp,
+			x0:         x + scale(lp.left, cols, width),
+			y0:         y + scale(lp.top, rows, height),
+			x1:         x + scale(lp.left+lp.width+1, cols, width),
+			y1:         y + scale(lp.top+lp.height+1, rows, height),
+		}
+		boxes = append(boxes, b)
+
+		for cx := b.x0; cx <= b.x1; cx++ {
+			for _, cy := range []int{b.y0, b.y1} {
+				if cx > b.x0 {
+					borders[[2]int{cx, cy}] |= left
+				}
+				if cx < b.x1 {
+					borders[[2]int{cx, cy}] |= right
+				}
+				highlighted[[2]int{cx, cy}] = highlighted[[2]int{cx, cy}] || lp.pane.Active
+			}
+		}
+		for cy := b.y0; cy <= b.y1; cy++ {
+			for _, cx := range []int{b.x0, b.x1} {
+				if cy > b.y0 {
+					borders[[2]int{cx, cy}] |= up
+				}
+				if cy < b.y1 {
+					borders[[2]int{cx, cy}] |= down
+				}
+				highlighted[[2]int{cx, cy}] = highlighted[[2]int{cx, cy}] || lp.pane.Active
+			}
+		}
+	}
+
+	// draw the borders, the ones of the active pane in the border color
+	runes := map[int]rune{
+		left:                     tview.Borders.Horizontal,
+		right:                    tview.Borders.Horizontal,
+		left | right:             tview.Borders.Horizontal,
+		up:                       tview.Borders.Vertical,
+		down:                     tview.Borders.Vertical,
+		up | down:                tview.Borders.Vertical,
+		down | right:             tview.Borders.TopLeft,
+		down | left:              tview.Borders.TopRight,
+		up | right:               tview.Borders.BottomLeft,
+		up | left:                tview.Borders.BottomRight,
+		up | down | right:        tview.Borders.LeftT,
+		up | down | left:         tview.Borders.RightT,
+		down | left | right:      tview.Borders.TopT,
+		up | left | right:        tview.Borders.BottomT,
+		up | down | left | right: tview.Borders.Cross,
+	}
+	for cell, connections := range borders {
+		color := conf.theme.text
+		if highlighted[cell] {
+			color = conf.theme.border
+		}
+		screen.SetContent(cell[0], cell[1], runes[connections], nil, tcell.StyleDefault.Foreground(color))
+	}
+
+	// draw the content of the panes in their box, with their index and command on the top border
+	for _, b := range boxes {
+		color := conf.theme.text
+		if b.pane.Active {
+			color = conf.theme.border
+		}
+		title := fmt.Sprintf(" %d %s ", b.pane.Index, b.pane.CurrentCommand)
+		tview.Print(screen, tview.Escape(title), b.x0+1, b.y0, b.x1-b.x0-1, tview.AlignLeft, color)
+
+		lines := b.lines
+		if h := b.y1 - b.y0 - 1; len(lines) > h {
+			lines = lines[len(lines)-max(h, 0):]
+		}
+		for idx, line := range lines {
+			tview.Print(screen, line, b.x0+1, b.y0+1+idx, b.x1-b.x0-1, tview.AlignLeft, conf.theme.text)
+		}
+	}
+}
+
+// Keybinding reading the history of the previewed pane, shared by the views.
+func (a *App) historyKeybinding() *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    'H',
+			display: "H",
+		},
+		action:      "history",
+		description: "Read and search the history of the previewed pane",
+		handler:     a.openHistory,
+	}
+}
+
+// Captures the history of the previewed pane and focuses the preview to scroll and search it.
+func (a *App) openHistory() {
+	p := a.preview
+	if p.pane == nil || p.history {
+		return
+	}
+
+	// the active pane is read when the layout of a window is previewed
+	p.set(p.pane, nil, nil)
+	if err := p.captureHistory(); err != nil {
+		a.ui.error(err)
+		a.refresh()
+		return
+	}
+
+	// the most recent lines are shown first
+	p.history = true
+	p.ScrollToEnd()
+	p.back = a.ui.GetFocus()
+	a.ui.SetFocus(p)
+}
+
+// Closes the history, the preview follows the selection again.
+func (a *App) closeHistory() {
+	p := a.preview
+	if !p.history {
+		return
+	}
+
+	p.history = false
+	p.content = ""
+	p.query = ""
+	p.Highlight()
+	p.SetRegions(false)
+	p.SetTitle(surroundSpace("Preview"))
+	if p.back != nil {
+		a.ui.SetFocus(p.back)
+		p.back = nil
+	}
+	a.refresh()
+}
+
+// Captures the history of the pane, keeping the search.
+func (p *Preview) captureHistory() error {
+	content, err := p.backend.CaptureHistory(p.pane, conf.HistoryLines)
+	if err != nil {
+		return err
+	}
+
+	p.content = content
+	p.render()
+
+	// the match may be past the end, or unset if there was none before
+	p.match = max(min(p.match, p.matches-1), 0)
+	p.highlight()
+	return nil
+}
+
+// Searches the history, highlighting the most recent match. An empty query clears the search.
+func (p *Preview) search(query string) {
+	p.query = query
+	p.render()
+	p.match = p.matches - 1
+	p.highlight()
+}
+
+// Highlights the match at the offset of the current one, wrapping around the ends.
+func (p *Preview) jump(offset int) {
+	if p.matches == 0 {
+		return
+	}
+	p.match = (p.match + offset + p.matches) % p.matches
+	p.highlight()
+}
+
+// Writes the history, with the matches of the search as regions in the mark color.
+func (p *Preview) render() {
+	p.Clear()
+	p.Highlight()
+	p.SetRegions(p.query != "")
+	p.matches = 0
+	if p.query == "" {
+		fmt.Fprint(tview.ANSIWriter(p), p.content)
+		return
+	}
+
+	// the colors of the pane are dropped so that the matches stand out
+	text := ansiSequence.ReplaceAllString(p.content, "")
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(p.query))
+
+	var b strings.Builder
+	last := 0
+	for i, loc := range re.FindAllStringIndex(text, -1) {
+		b.WriteString(tview.Escape(text[last:loc[0]]))
+		fmt.Fprintf(&b, `["%d"][%s:%s]%s[-:-][""]`, i, conf.theme.selectionText, conf.theme.mark, tview.Escape(text[loc[0]:loc[1]]))
+		last = loc[1]
+		p.matches++
+	}
+	b.WriteString(tview.Escape(text[last:]))
+	p.SetText(b.String())
+}
+
+// Highlights the current match and scrolls to it.
+func (p *Preview) highlight() {
+	if p.matches > 0 {
+		p.Highlight(strconv.Itoa(p.match))
+		p.ScrollToHighlight()
+	}
+	p.setHistoryTitle()
+}
+
+// Sets the title of the history, with the state of the search.
+func (p *Preview) setHistoryTitle() {
+	title := "History of " + p.pane.Id
+	switch {
+	case p.query == "":
+	case p.matches == 0:
+		title += fmt.Sprintf(" - /%s (no match)", p.query)
+	default:
+		title += fmt.Sprintf(" - /%s (%d/%d)", p.query, p.match+1, p.matches)
+	}
+	p.SetTitle(surroundSpace(tview.Escape(title)))
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Creates a session whose first window is split side by side, %0 on the left and %1 on the right.
+func newPreviewSession(t *testing.T, f *fakeBackend) (*gotmux.Session, *gotmux.Window) {
+	t.Helper()
+	s := newTestSessions(t, f, "alpha")[0]
+	op := &gotmux.SplitWindowOptions{SplitDirection: gotmux.PaneSplitDirectionHorizontal}
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, op); err != nil {
+		t.Fatal(err)
+	}
+	w, err := f.GetWindow("@0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, w
+}
+
+func TestTreePreviewed(t *testing.T) {
+	f := newFakeBackend()
+	s, _ := newPreviewSession(t, f)
+	a := newTestApp(t, f)
+
+	cases := []struct {
+		node   *tview.TreeNode
+		window string
+		pane   string
+	}{
+		{a.tree.reveal(s.Id, "", ""), "@0", ""},
+		{a.tree.reveal(s.Id, "@0", ""), "@0", ""},
+		{a.tree.reveal(s.Id, "@0", "%1"), "", "%1"},
+	}
+	for _, c := range cases {
+		window, pane, err := a.tree.previewed(c.node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		windowId, paneId := "", ""
+		if window != nil {
+			windowId = window.Id
+		}
+		if pane != nil {
+			paneId = pane.Id
+		}
+		if windowId != c.window || paneId != c.pane {
+			t.Fatalf("previewed of %s = %q %q, want %q %q", unwrapNode(c.node).id(), windowId, paneId, c.window, c.pane)
+		}
+	}
+}
+
+func TestPreviewWindowLayout(t *testing.T) {
+	f := newFakeBackend()
+	_, w := newPreviewSession(t, f)
+	f.setContent("%1", "$ make\nok\n\n\n")
+	a := newTestApp(t, f)
+	p := a.preview
+
+	p.show(w, nil)
+	if p.window == nil || p.window.Id != "@0" {
+		t.Fatal("window layout not previewed")
+	}
+	if p.pane == nil || p.pane.Id != "%0" {
+		t.Fatalf("previewed pane = %v, want the active %%0", p.pane)
+	}
+
+	want := []struct {
+		id                       string
+		left, top, width, height int
+	}{
+		{"%0", 0, 0, 39, 24},
+		{"%1", 40, 0, 40, 24},
+	}
+	if len(p.layout) != len(want) {
+		t.Fatalf("layout panes = %d, want %d", len(p.layout), len(want))
+	}
+	for idx, lp := range p.layout {
+		wp := want[idx]
+		if lp.pane.Id != wp.id || lp.left != wp.left || lp.top != wp.top || lp.width != wp.width || lp.height != wp.height {
+			t.Fatalf("layout pane %d = %s at %d,%d %dx%d, want %+v", idx, lp.pane.Id, lp.left, lp.top, lp.width, lp.height, wp)
+		}
+	}
+	if lines := p.layout[1].lines; len(lines) != 2 || lines[1] != "ok" {
+		t.Fatalf("lines of %%1 = %q, want the trailing blank lines dropped", lines)
+	}
+
+	// a pane previewed alone replaces the layout
+	p.show(nil, &gotmux.Pane{Id: "%1"})
+	if p.window != nil || p.layout != nil {
+		t.Fatal("layout kept when previewing a pane")
+	}
+}
+
+func TestPreviewDrawLayout(t *testing.T) {
+	f := newFakeBackend()
+	_, w := newPreviewSession(t, f)
+	f.setContent("%1", "$ make\nok\n")
+	a := newTestApp(t, f)
+	p := a.preview
+	p.show(w, nil)
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(100, 30)
+
+	// the window fits in the inner rect, so it is drawn unscaled from its top left corner
+	p.SetRect(0, 0, 100, 30)
+	p.Draw(screen)
+
+	cells := []struct {
+		x, y int
+		want rune
+	}{
+		{1, 1, tview.Borders.TopLeft},
+		{41, 1, tview.Borders.TopT},
+		{82, 1, tview.Borders.TopRight},
+		{41, 10, tview.Borders.Vertical},
+		{1, 26, tview.Borders.BottomLeft},
+		{41, 26, tview.Borders.BottomT},
+		{82, 26, tview.Borders.BottomRight},
+		{42, 3, 'o'},
+		{43, 3, 'k'},
+	}
+	for _, c := range cells {
+		if got, _, _, _ := screen.GetContent(c.x, c.y); got != c.want {
+			t.Fatalf("cell %d,%d = %q, want %q", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	f := newFakeBackend()
+	s := newTestSessions(t, f, "alpha")[0]
+	f.setContent("%0", "$ make\nerror: a\nok\nError: b\nerror: c\n")
+	a := newTestApp(t, f)
+	p := a.preview
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%0"))
+	p.show(nil, &gotmux.Pane{Id: "%0"})
+	press(a, tcell.KeyRune, 'H')
+	if !p.history || a.ui.GetFocus() != p {
+		t.Fatal("history not opened")
+	}
+
+	// the most recent match is highlighted first, the search is case insensitive
+	press(a, tcell.KeyRune, '/')
+	typeText(a, "error")
+	press(a, tcell.KeyEnter, 0)
+	if p.matches != 3 || p.match != 2 {
+		t.Fatalf("match %d of %d, want 2 of 3", p.match, p.matches)
+	}
+	press(a, tcell.KeyRune, 'n')
+	if p.match != 0 {
+		t.Fatalf("next match = %d, want the first after wrapping", p.match)
+	}
+	press(a, tcell.KeyRune, 'N')
+	press(a, tcell.KeyRune, 'N')
+	if p.match != 1 {
+		t.Fatalf("previous match = %d, want 1", p.match)
+	}
+
+	// Esc clears the search first, then closes the history
+	press(a, tcell.KeyEscape, 0)
+	if p.query != "" || !p.history {
+		t.Fatal("search not cleared")
+	}
+	press(a, tcell.KeyEscape, 0)
+	if p.history || a.ui.GetFocus() != a.tree {
+		t.Fatal("history not closed")
+	}
+}
+
+func TestHistoryRefreshKeepsMatch(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	f.setContent("%0", "$ make\n")
+	a := newTestApp(t, f)
+	p := a.preview
+
+	p.show(nil, &gotmux.Pane{Id: "%0"})
+	a.openHistory()
+	p.search("error")
+	if p.matches != 0 {
+		t.Fatalf("matches = %d, want 0", p.matches)
+	}
+
+	// the output printed since then matches
+	f.setContent("%0", "$ make\nerror: a\nerror: b\n")
+	if err := p.captureHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if p.matches != 2 || p.match != 0 {
+		t.Fatalf("match %d of %d, want 0 of 2", p.match, p.matches)
+	}
+	if got := p.GetHighlights(); len(got) != 1 || got[0] != "0" {
+		t.Fatalf("highlights = %v, want [0]", got)
+	}
+}
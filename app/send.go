@@ -0,0 +1,187 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Way of typing text in the panes.
+type sendMode struct {
+	// name of the mode, in the titles
+	name string
+
+	// set to type the text as is and press Enter,
+	// otherwise the text lists tmux keys separated by spaces, i.e. "q", "C-c" or "Up Enter"
+	command bool
+}
+
+var (
+	commandMode = &sendMode{name: "command", command: true}
+	keysMode    = &sendMode{name: "keys"}
+)
+
+// Keybindings typing commands and keys in the panes without attaching, shared by the views.
+func (a *App) sendKeybindings(selected func() *target) []*Keybinding {
+	// opens the editor of the text to send to the selected pane
+	send := func(mode *sendMode) func() {
+		return func() {
+			item := selected()
+			if item == nil || item.session == nil || item.pane == nil {
+				return
+			}
+
+			title := fmt.Sprintf("Send %s to %s:%d.%d", mode.name, item.session.Name, item.window.Index, item.pane.Index)
+			a.ui.editor(title, "", func(text string) {
+				if err := a.send(item.pane, mode, text); err != nil {
+					a.ui.error(err)
+				}
+				a.sent()
+			})
+		}
+	}
+
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'i',
+				display: "i",
+			},
+			action:      "send-command",
+			description: "Type a command in the pane and run it",
+			handler:     send(commandMode),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'e',
+				display: "e",
+			},
+			action:      "send-keys",
+			description: "Send keys to the pane, i.e. q, C-c or Up Enter",
+			handler:     send(keysMode),
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'b',
+				display: "b",
+			},
+			action:      "broadcast",
+			description: "Send a command or keys to all the panes of the window or session",
+			handler: func() {
+				item := selected()
+				if item == nil || item.session == nil {
+					return
+				}
+				a.broadcast(item)
+			},
+		},
+	}
+}
+
+// Types the text in the pane according to the mode.
+func (a *App) send(pane *gotmux.Pane, mode *sendMode, text string) error {
+	if !mode.command {
+		keys := strings.Fields(text)
+		if len(keys) == 0 {
+			return nil
+		}
+		return a.backend.SendKeys(pane, false, keys...)
+	}
+
+	// an empty command only presses Enter
+	if text != "" {
+		if err := a.backend.SendKeys(pane, true, text); err != nil {
+			return err
+		}
+	}
+	return a.backend.SendKeys(pane, false, "Enter")
+}
+
+// Refreshes the views, and the preview again once the panes had time to print the output.
+func (a *App) sent() {
+	a.refresh()
+	a.capturePreview()
+}
+
+// Asks for the panes to send to, the window of the item or its whole session, and the text,
+// then sends it to every pane once the listed panes are confirmed.
+func (a *App) broadcast(item *target) {
+	type choice struct {
+		mode   *sendMode
+		window *gotmux.Window
+	}
+
+	choices := make([]*choice, 0)
+	texts := make([]string, 0)
+	for _, mode := range []*sendMode{commandMode, keysMode} {
+		// only the session is offered when a session is selected
+		if item.window != nil {
+			choices = append(choices, &choice{mode, item.window})
+			texts = append(texts, fmt.Sprintf("%s to the panes of window %s:%d", mode.name, item.session.Name, item.window.Index))
+		}
+		choices = append(choices, &choice{mode, nil})
+		texts = append(texts, fmt.Sprintf("%s to the panes of session %s", mode.name, item.session.Name))
+	}
+
+	a.ui.picker("Broadcast", texts, nil, func(idx int, ok bool) {
+		if !ok {
+			return
+		}
+
+		c := choices[idx]
+		a.ui.editor("Broadcast "+c.mode.name, "", func(text string) {
+			panes, err := a.broadcastPanes(item.session, c.window)
+			if err != nil {
+				a.ui.error(err)
+				return
+			}
+
+			lines := make([]string, len(panes))
+			for idx, p := range panes {
+				lines[idx] = fmt.Sprintf("send %q to %s", text, describe(p))
+			}
+			a.ui.confirmPlan("Broadcast "+c.mode.name+" ?", lines, func() {
+				errs := make([]error, 0)
+				for idx, p := range panes {
+					if err := a.send(p.pane, c.mode, text); err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", lines[idx], err))
+					}
+				}
+
+				a.sent()
+				if err := errors.Join(errs...); err != nil {
+					a.ui.error(err)
+				}
+			})
+		})
+	})
+}
+
+// Lists the panes of the window, or of all the windows of the session if the window is nil.
+func (a *App) broadcastPanes(session *gotmux.Session, window *gotmux.Window) ([]*target, error) {
+	windows := []*gotmux.Window{window}
+	if window == nil {
+		var err error
+		if windows, err = a.backend.ListWindows(session); err != nil {
+			return nil, err
+		}
+	}
+
+	targets := make([]*target, 0)
+	for _, w := range windows {
+		panes, err := a.backend.ListPanes(w)
+		if err != nil {
+			return nil, err
+		}
+		for _, pane := range panes {
+			targets = append(targets, &target{session: session, window: w, pane: pane})
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestSendFromTree(t *testing.T) {
+	f := newFakeBackend()
+	s := newTestSessions(t, f, "alpha")[0]
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", "%0"))
+	press(a, tcell.KeyRune, 'i')
+	typeText(a, "make test")
+	press(a, tcell.KeyEnter, 0)
+	press(a, tcell.KeyRune, 'e')
+	typeText(a, "C-c  Up Enter")
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	want := []string{"make test", "Enter", "C-c", "Up", "Enter"}
+	if got := f.keys["%0"]; !slices.Equal(got, want) {
+		t.Fatalf("keys = %q, want %q", got, want)
+	}
+}
+
+func TestSendEmpty(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+	pane := &gotmux.Pane{Id: "%0"}
+
+	// an empty command presses Enter, empty keys send nothing
+	if err := a.send(pane, commandMode, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.send(pane, keysMode, "  "); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.keys["%0"]; !slices.Equal(got, []string{"Enter"}) {
+		t.Fatalf("keys = %q, want [Enter]", got)
+	}
+}
+
+func TestBroadcastPanes(t *testing.T) {
+	f := newFakeBackend()
+	s := newTestSessions(t, f, "alpha")[0]
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	w, err := f.NewWindow(s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	ids := func(targets []*target) []string {
+		out := make([]string, 0)
+		for _, item := range targets {
+			out = append(out, item.pane.Id)
+		}
+		return out
+	}
+
+	window, err := f.GetWindow("@0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	panes, err := a.broadcastPanes(s, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(panes); !slices.Equal(got, []string{"%0", "%1"}) {
+		t.Fatalf("panes of the window = %v, want [%%0 %%1]", got)
+	}
+
+	panes, err = a.broadcastPanes(s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(panes); len(got) != 3 || panes[2].window.Id != w.Id {
+		t.Fatalf("panes of the session = %v, want the 3 panes of both windows", got)
+	}
+}
+
+func TestBroadcastFromTree(t *testing.T) {
+	f := newFakeBackend()
+	s := newTestSessions(t, f, "alpha")[0]
+	if _, err := f.SplitPane(&gotmux.Pane{Id: "%0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewWindow(s, nil); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	// the first choice sends a command to the panes of the window
+	a.tree.SetCurrentNode(a.tree.reveal(s.Id, "@0", ""))
+	press(a, tcell.KeyRune, 'b')
+	press(a, tcell.KeyEnter, 0)
+	typeText(a, "ls")
+	press(a, tcell.KeyEnter, 0)
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	for _, id := range []string{"%0", "%1"} {
+		if got := f.keys[id]; !slices.Equal(got, []string{"ls", "Enter"}) {
+			t.Fatalf("keys of %s = %q, want [ls Enter]", id, got)
+		}
+	}
+	if got := f.keys["%2"]; len(got) > 0 {
+		t.Fatalf("keys of the other window = %q, want none", got)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/rivo/tview"
+)
+
+// Dimensions of the new session form.
+const (
+	sessionFormWidth  = 60
+	sessionFormHeight = 14
+)
+
+// Opens the form creating a new session with its start directory and initial command.
+// The session is then attached to, or selected in the visible view to stay in tmuxman.
+func (a *App) newSession() {
+	sessions, err := a.backend.ListSessions()
+	if err != nil {
+		a.ui.error(err)
+		return
+	}
+
+	f := tview.NewForm()
+	f.AddInputField("Name", "", 0, nil, nil)
+	f.AddInputField("Start directory", "", 0, nil, nil)
+	f.AddInputField("Command", "", 0, nil, nil)
+	f.AddCheckbox("Attach", true, nil)
+	name := f.GetFormItemByLabel("Name").(*tview.InputField)
+	dir := f.GetFormItemByLabel("Start directory").(*tview.InputField)
+	command := f.GetFormItemByLabel("Command").(*tview.InputField)
+	attach := f.GetFormItemByLabel("Attach").(*tview.Checkbox)
+
+	// complete the directories as the path is typed
+	dir.SetAutocompleteFunc(completeDir)
+
+	f.AddButton("Create", nil)
+	f.AddButton("Cancel", a.ui.closeModal)
+	m := a.ui.form("New session", f, sessionFormWidth, sessionFormHeight)
+
+	// report invalid values as they are typed
+	validate := func() bool {
+		msg := validateSession(name.GetText(), expandHome(dir.GetText()), sessions)
+		m.SetText("[red]" + tview.Escape(msg))
+		return msg == ""
+	}
+	name.SetChangedFunc(func(string) { validate() })
+	dir.SetChangedFunc(func(string) { validate() })
+
+	f.GetButton(0).SetSelectedFunc(func() {
+		// check again since sessions may have been created in the meantime
+		if sessions, err = a.backend.ListSessions(); err != nil {
+			a.ui.closeModal()
+			a.ui.error(err)
+			return
+		}
+		if !validate() {
+			return
+		}
+		a.ui.closeModal()
+
+		session, err := a.backend.NewSession(&gotmux.SessionOptions{
+			Name:           name.GetText(),
+			StartDirectory: expandHome(dir.GetText()),
+			ShellCommand:   command.GetText(),
+		}, nil)
+		if err != nil {
+			a.ui.error(err)
+			return
+		}
+
+		if attach.IsChecked() {
+			if err := a.attach(session); err != nil {
+				a.ui.error(err)
+			}
+		}
+
+		if err := a.jump(&target{session: session}); err != nil {
+			a.ui.error(err)
+		}
+	})
+}
+
+// Checks the name and start directory of a new session against the existing sessions.
+// Returns a message describing the problem, empty if they are valid.
+func validateSession(name string, dir string, sessions []*gotmux.Session) string {
+	// tmux does not allow these in names, it would replace them
+	if strings.ContainsAny(name, ":.") {
+		return "names can't contain ':' or '.'"
+	}
+
+	for _, s := range sessions {
+		if s.Name == name {
+			return "duplicate session: " + name
+		}
+	}
+
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "no such directory: " + dir
+		}
+	}
+
+	return ""
+}
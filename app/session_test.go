@@ -0,0 +1,61 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestValidateSession(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessions := []*gotmux.Session{{Name: "alpha"}}
+
+	cases := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"beta", "", ""},
+		{"beta", dir, ""},
+		{"alpha", "", "duplicate session"},
+		{"a:b", "", "can't contain"},
+		{"a.b", "", "can't contain"},
+		{"beta", filepath.Join(dir, "missing"), "no such directory"},
+		{"beta", file, "no such directory"},
+	}
+	for _, c := range cases {
+		got := validateSession(c.name, c.dir, sessions)
+		if c.want == "" && got != "" || !strings.Contains(got, c.want) {
+			t.Fatalf("validateSession(%q, %q) = %q, want %q", c.name, c.dir, got, c.want)
+		}
+	}
+}
+
+func TestNewSessionRejected(t *testing.T) {
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	// type a duplicate name, then go to the create button
+	a.newSession()
+	typeText(a, "alpha")
+	for range 4 {
+		press(a, tcell.KeyTab, 0)
+	}
+	press(a, tcell.KeyEnter, 0)
+
+	if !a.ui.root.HasPage(modalName) {
+		t.Fatal("form closed with an invalid name")
+	}
+	if sessions, _ := f.ListSessions(); len(sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(sessions))
+	}
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Snapshot of all the sessions, saved to restore them once the tmux server restarts.
+type state struct {
+	// time the state was saved
+	Saved time.Time `json:"saved"`
+
+	// sessions as templates, with the scrollback of their panes if it was saved
+	Sessions []*template `json:"sessions"`
+}
+
+// Path of the state file.
+func statePath() string {
+	return filepath.Join(dataDir(), "state.json")
+}
+
+// Path of the directory holding the scrollback of the panes of the saved state.
+func scrollbackDir() string {
+	return filepath.Join(dataDir(), "scrollback")
+}
+
+// Saves all the sessions to the state file, replacing the previous state.
+// If scrollback is set, the scrollback of every pane is saved along.
+// Returns the number of saved sessions.
+func (a *App) saveState(scrollback bool) (int, error) {
+	sessions, err := a.backend.ListSessions()
+	if err != nil {
+		return 0, err
+	}
+
+	// the scrollback of the previous state is replaced as well
+	dir := ""
+	if scrollback {
+		dir = scrollbackDir()
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, err
+		}
+	}
+
+	s := &state{Saved: time.Now()}
+	for _, session := range sessions {
+		t, err := a.sessionTemplate(session, dir)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", session.Name, err)
+		}
+		s.Sessions = append(s.Sessions, t)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	// write to a temporary file first to never leave a truncated state
+	path := statePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return 0, err
+	}
+	return len(s.Sessions), os.Rename(tmp, path)
+}
+
+// Saves the scrollback of the pane to a file of the directory, named after the pane.
+// Returns the path of the file.
+func (a *App) saveScrollback(pane *gotmux.Pane, dir string) (string, error) {
+	content, err := a.backend.CaptureHistory(pane, 0)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, strings.TrimPrefix(pane.Id, "%")+".txt")
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
+
+// Recreates the sessions of the state file, except the ones that already exist.
+// Returns the names of the restored sessions, and an error for every session
+// that could not be restored, or only partially.
+func (a *App) restoreState() ([]string, error) {
+	path := statePath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("no saved state: " + path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &state{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sessions, err := a.backend.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make([]string, 0)
+	errs := make([]error, 0)
+	for _, t := range s.Sessions {
+		exists := slices.ContainsFunc(sessions, func(session *gotmux.Session) bool {
+			return session.Name == t.Name
+		})
+		if exists {
+			errs = append(errs, fmt.Errorf("%s: session already exists", t.Name))
+			continue
+		}
+
+		if _, err := a.createFromTemplate(t, t.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name, err))
+			continue
+		}
+		restored = append(restored, t.Name)
+	}
+
+	return restored, errors.Join(errs...)
+}
+
+// Saves the sessions, or restores them, and prints the outcome.
+func (a *App) runState(restore bool) error {
+	if !restore {
+		n, err := a.saveState(a.scrollback)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("saved %d sessions to %s\n", n, statePath())
+		return nil
+	}
+
+	restored, err := a.restoreState()
+	fmt.Printf("restored %d sessions\n", len(restored))
+	for _, name := range restored {
+		fmt.Println(name)
+	}
+	return err
+}
+
+// Keybindings saving and restoring the sessions, shared by the views.
+func (a *App) stateKeybindings() []*Keybinding {
+	return []*Keybinding{
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'S',
+				display: "S",
+			},
+			action:      "save",
+			description: "Save all sessions",
+			handler: func() {
+				a.ui.confirm("Replace the saved sessions?", func(b bool) {
+					if !b {
+						return
+					}
+
+					n, err := a.saveState(a.scrollback)
+					if err != nil {
+						a.ui.error(err)
+						return
+					}
+					a.ui.message("Saved", "Saved "+strconv.Itoa(n)+" sessions to "+statePath())
+				})
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'L',
+				display: "L",
+			},
+			action:      "restore",
+			description: "Restore saved sessions",
+			handler: func() {
+				restored, err := a.restoreState()
+				a.refresh()
+
+				msg := "Restored " + strconv.Itoa(len(restored)) + " sessions"
+				if len(restored) > 0 {
+					msg += ": " + strings.Join(restored, ", ")
+				}
+				if err != nil {
+					a.ui.error(fmt.Errorf("%s, failed:\n%w", msg, err))
+					return
+				}
+				a.ui.message("Restored", msg)
+			},
+		},
+	}
+}
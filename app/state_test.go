@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+func TestSaveAndRestoreState(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[1], &gotmux.NewWindowOptions{WindowName: "logs", StartDirectory: "/var/log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	panes, err := f.ListPanes(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.setContent(panes[0].Id, "$ tail syslog\nboot\n")
+
+	n, err := newApp(f).saveState(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("saved %d sessions, want 2", n)
+	}
+
+	// alpha survived the restart of the server, beta is recreated
+	restarted := newFakeBackend()
+	newTestSessions(t, restarted, "alpha")
+	restored, err := newApp(restarted).restoreState()
+	if err == nil || !strings.Contains(err.Error(), "alpha: session already exists") {
+		t.Fatalf("err = %v, want alpha reported as existing", err)
+	}
+	if !slices.Equal(restored, []string{"beta"}) {
+		t.Fatalf("restored = %v, want [beta]", restored)
+	}
+
+	all, err := restarted.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, err := restarted.ListWindows(all[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1].Name != "logs" {
+		t.Fatalf("windows = %v, want the logs window restored", windows)
+	}
+	panes, err = restarted.ListPanes(windows[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if panes[0].CurrentPath != "/var/log" {
+		t.Fatalf("path = %q, want /var/log", panes[0].CurrentPath)
+	}
+
+	// the scrollback is printed back in the pane from its file
+	keys := restarted.keys[panes[0].Id]
+	if len(keys) != 2 || keys[1] != "Enter" {
+		t.Fatalf("keys = %v, want the scrollback printed", keys)
+	}
+	path := strings.Trim(strings.TrimPrefix(keys[0], " clear; cat "), "'")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "$ tail syslog\nboot\n" {
+		t.Fatalf("scrollback = %q", content)
+	}
+}
+
+func TestRestoreStateMissing(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if _, err := newApp(newFakeBackend()).restoreState(); err == nil {
+		t.Fatal("expected restoring without a saved state to fail")
+	}
+}
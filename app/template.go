@@ -0,0 +1,524 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Session template, loaded from a YAML or TOML file of the templates directory.
+//
+// Example:
+//
+//	name: dev
+//	root: ~/code/app
+//	env:
+//	  APP_ENV: development
+//	windows:
+//	  - name: editor
+//	    panes:
+//	      - command: vim
+//	  - name: server
+//	    layout: even-horizontal
+//	    panes:
+//	      - command: npm run dev
+//	      - root: logs
+//	        split: horizontal
+type template struct {
+	// name of the session, the file name by default
+	Name string `yaml:"name,omitempty" toml:"name,omitempty" json:"name,omitempty"`
+
+	// start directory of the session
+	Root string `yaml:"root,omitempty" toml:"root,omitempty" json:"root,omitempty"`
+
+	// environment of the session
+	Env map[string]string `yaml:"env,omitempty" toml:"env,omitempty" json:"env,omitempty"`
+
+	Windows []windowTemplate `yaml:"windows,omitempty" toml:"windows,omitempty" json:"windows,omitempty"`
+
+	// file the template is loaded from
+	path string
+}
+
+// Window of a session template.
+type windowTemplate struct {
+	Name string `yaml:"name,omitempty" toml:"name,omitempty" json:"name,omitempty"`
+
+	// start directory, relative to the root of the session
+	Root string `yaml:"root,omitempty" toml:"root,omitempty" json:"root,omitempty"`
+
+	// layout applied once all the panes are created, i.e. tiled or main-vertical
+	Layout string `yaml:"layout,omitempty" toml:"layout,omitempty" json:"layout,omitempty"`
+
+	// window selected once the session is created, the first one by default
+	Active bool `yaml:"active,omitempty" toml:"active,omitempty" json:"active,omitempty"`
+
+	Panes []paneTemplate `yaml:"panes,omitempty" toml:"panes,omitempty" json:"panes,omitempty"`
+}
+
+// Pane of a window template.
+type paneTemplate struct {
+	// start directory, relative to the root of the window
+	Root string `yaml:"root,omitempty" toml:"root,omitempty" json:"root,omitempty"`
+
+	// command typed in the shell of the pane
+	Command string `yaml:"command,omitempty" toml:"command,omitempty" json:"command,omitempty"`
+
+	// direction the previous pane is split in to create this one, horizontal or vertical (default)
+	Split string `yaml:"split,omitempty" toml:"split,omitempty" json:"split,omitempty"`
+
+	// file holding the scrollback printed in the pane, only saved in the state file
+	Scrollback string `yaml:"-" toml:"-" json:"scrollback,omitempty"`
+}
+
+// Path of the directory holding the session templates.
+func templatesDir() string {
+	return filepath.Join(configDir(), "templates")
+}
+
+// Loads all the templates of the directory, ignoring the files that are not YAML or TOML.
+// Returns the templates and an error for each file that can't be loaded.
+func loadTemplates(dir string) ([]*template, []error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	templates := make([]*template, 0)
+	errs := make([]error, 0)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		t, err := loadTemplate(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if t != nil {
+			templates = append(templates, t)
+		}
+	}
+
+	return templates, errs
+}
+
+// Loads a template file. Returns nil if the file is not YAML or TOML.
+func loadTemplate(path string) (*template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &template{path: path}
+	ext := filepath.Ext(path)
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, t)
+	case ".toml":
+		err = toml.Unmarshal(data, t)
+	default:
+		return nil, nil
+	}
+	if err == nil {
+		err = t.validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", filepath.Base(path), err)
+	}
+
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), ext)
+	}
+	return t, nil
+}
+
+// Checks the values that tmux would only reject halfway through creating the session.
+func (t *template) validate() error {
+	if strings.ContainsAny(t.Name, ":.") {
+		return errors.New("names can't contain ':' or '.'")
+	}
+
+	for _, w := range t.Windows {
+		for _, p := range w.Panes {
+			if p.Split != "" && p.Split != "horizontal" && p.Split != "vertical" {
+				return fmt.Errorf("invalid split: %s", p.Split)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Keybinding opening the template picker, shared by the views.
+func (a *App) templateKeybinding() *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    'A',
+			display: "A",
+		},
+		action:      "new-from-template",
+		description: "Create a new session from a template",
+		handler:     a.pickTemplate,
+	}
+}
+
+// Opens a picker over the templates, then prompts for the name of the session to create.
+// Files that can't be loaded are listed after the templates, choosing one shows its error.
+func (a *App) pickTemplate() {
+	dir := templatesDir()
+	templates, errs := loadTemplates(dir)
+	if len(templates) == 0 && len(errs) == 0 {
+		a.ui.message("Templates", "There is no template yet, add YAML or TOML files to "+dir)
+		return
+	}
+
+	candidates := make([]string, 0, len(templates)+len(errs))
+	for _, t := range templates {
+		candidates = append(candidates, fmt.Sprintf("%s  (%s)", t.Name, filepath.Base(t.path)))
+	}
+	for _, err := range errs {
+		logError(err)
+		candidates = append(candidates, "invalid "+err.Error())
+	}
+
+	a.ui.finder("New session from template", nil, candidates, nil, func(idx int, _ tcell.Key) {
+		if idx >= len(templates) {
+			a.ui.error(errs[idx-len(templates)])
+			return
+		}
+
+		t := templates[idx]
+		a.ui.editor("New session name", t.Name, func(name string) {
+			session, err := a.createFromTemplate(t, name)
+			if err != nil {
+				a.ui.error(err)
+			}
+			if session == nil {
+				return
+			}
+
+			if err := a.jump(&target{session: session}); err != nil {
+				a.ui.error(err)
+			}
+		})
+	})
+}
+
+// Creates a session from the template, with its windows and panes.
+// On failure, the partially created session is returned along with the error.
+func (a *App) createFromTemplate(t *template, name string) (*gotmux.Session, error) {
+	root := expandHome(t.Root)
+	windows := t.Windows
+	if len(windows) == 0 {
+		windows = []windowTemplate{{}}
+	}
+
+	var session *gotmux.Session
+	var active *gotmux.Window
+	for _, wt := range windows {
+		windowRoot := joinRoot(root, wt.Root)
+		panes := wt.Panes
+		if len(panes) == 0 {
+			panes = []paneTemplate{{}}
+		}
+
+		// the first window comes with the session
+		var window *gotmux.Window
+		var err error
+		if session == nil {
+			session, err = a.backend.NewSession(&gotmux.SessionOptions{
+				Name:           name,
+				StartDirectory: joinRoot(windowRoot, panes[0].Root),
+			}, t.Env)
+			if err != nil {
+				return nil, err
+			}
+
+			window, err = a.firstWindow(session)
+			if err == nil && wt.Name != "" {
+				err = a.backend.RenameWindow(window, wt.Name)
+			}
+			active = window
+		} else {
+			window, err = a.backend.NewWindow(session, &gotmux.NewWindowOptions{
+				WindowName:     wt.Name,
+				StartDirectory: joinRoot(windowRoot, panes[0].Root),
+				DoNotAttach:    true,
+			})
+		}
+		if err != nil {
+			return session, err
+		}
+		if wt.Active {
+			active = window
+		}
+
+		if err := a.createPanes(window, windowRoot, panes); err != nil {
+			return session, err
+		}
+
+		if wt.Layout != "" {
+			if err := a.backend.SelectLayout(window, wt.Layout); err != nil {
+				return session, err
+			}
+		}
+	}
+
+	return session, a.backend.SelectWindow(session, active)
+}
+
+// Gets the window a session is created with.
+func (a *App) firstWindow(session *gotmux.Session) (*gotmux.Window, error) {
+	windows, err := a.backend.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, errors.New("no window in session: " + session.Name)
+	}
+
+	return windows[0], nil
+}
+
+// Creates the panes of a new window, which already has the first one,
+// and types their commands.
+func (a *App) createPanes(window *gotmux.Window, root string, panes []paneTemplate) error {
+	existing, err := a.backend.ListPanes(window)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return errors.New("no pane in window: " + window.Id)
+	}
+
+	pane := existing[0]
+	for idx, pt := range panes {
+		if idx > 0 {
+			direction := gotmux.PaneSplitDirectionVertical
+			if pt.Split == "horizontal" {
+				direction = gotmux.PaneSplitDirectionHorizontal
+			}
+
+			pane, err = a.backend.SplitPane(pane, &gotmux.SplitWindowOptions{
+				SplitDirection: direction,
+				StartDirectory: joinRoot(root, pt.Root),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// print the saved scrollback, hidden from the shell history by the leading space
+		if pt.Scrollback != "" {
+			if err := a.backend.SendKeys(pane, true, " clear; cat "+shellQuote(pt.Scrollback)); err != nil {
+				return err
+			}
+			if err := a.backend.SendKeys(pane, false, "Enter"); err != nil {
+				return err
+			}
+		}
+
+		// type the command so that the shell remains once it exits
+		if pt.Command != "" {
+			if err := a.backend.SendKeys(pane, true, pt.Command); err != nil {
+				return err
+			}
+			if err := a.backend.SendKeys(pane, false, "Enter"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Keybinding exporting the selected session as a template, shared by the views.
+func (a *App) exportKeybinding(selected func() *gotmux.Session) *Keybinding {
+	return &Keybinding{
+		key: &Key{
+			key:     tcell.KeyRune,
+			rune:    'E',
+			display: "E",
+		},
+		action:      "export-template",
+		description: "Export session as a template",
+		handler: func() {
+			if session := selected(); session != nil {
+				a.exportTemplate(session)
+			}
+		},
+	}
+}
+
+// Prompts for the name of the file to export the session to, in the templates directory.
+// The extension of the file picks the format, YAML or TOML.
+func (a *App) exportTemplate(session *gotmux.Session) {
+	t, err := a.sessionTemplate(session, "")
+	if err != nil {
+		a.ui.error(err)
+		return
+	}
+
+	a.ui.editor("Export to template file", session.Name+".yaml", func(name string) {
+		path := filepath.Join(templatesDir(), filepath.Base(name))
+		write := func() {
+			if err := t.write(path); err != nil {
+				a.ui.error(err)
+			}
+		}
+
+		if _, err := os.Stat(path); err == nil {
+			a.ui.confirm("Overwrite "+filepath.Base(path)+" ?", func(b bool) {
+				if b {
+					write()
+				}
+			})
+			return
+		}
+		write()
+	})
+}
+
+// Builds a template recreating the session as it is: its windows with their exact layout,
+// and the current path and command of their panes (see paneCommand).
+// Paths inside the start directory of the session are made relative to it.
+// If the scrollback dir is set, the scrollback of the panes is saved to files in it.
+func (a *App) sessionTemplate(session *gotmux.Session, scrollbackDir string) (*template, error) {
+	t := &template{
+		Name: session.Name,
+		Root: session.Path,
+	}
+
+	windows, err := a.backend.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range windows {
+		panes, err := a.backend.ListPanes(w)
+		if err != nil {
+			return nil, err
+		}
+
+		wt := windowTemplate{
+			Name:   w.Name,
+			Layout: w.Layout,
+			Active: w.Active,
+		}
+		for _, p := range panes {
+			pt := paneTemplate{}
+			if rel, err := filepath.Rel(t.Root, p.CurrentPath); t.Root == "" || err != nil || strings.HasPrefix(rel, "..") {
+				pt.Root = p.CurrentPath
+			} else if rel != "." {
+				pt.Root = rel
+			}
+
+			pt.Command = paneCommand(p)
+
+			if scrollbackDir != "" {
+				if pt.Scrollback, err = a.saveScrollback(p, scrollbackDir); err != nil {
+					return nil, err
+				}
+			}
+			wt.Panes = append(wt.Panes, pt)
+		}
+		t.Windows = append(t.Windows, wt)
+	}
+
+	return t, nil
+}
+
+// Writes the template to the file, in YAML or TOML depending on its extension.
+func (t *template) write(path string) error {
+	var b bytes.Buffer
+	var err error
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		e := yaml.NewEncoder(&b)
+		e.SetIndent(2)
+		err = e.Encode(t)
+	case ".toml":
+		err = toml.NewEncoder(&b).Encode(t)
+	default:
+		return errors.New("template files must be .yaml, .yml or .toml: " + filepath.Base(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
+
+// Gets the command to type again in the pane, empty if it runs a shell since it is started anyway.
+// The command the pane was started with is kept whole, but tmux only knows the commands typed
+// in its shell by the name of their process, i.e. "npm" for "npm run dev", without arguments.
+func paneCommand(p *gotmux.Pane) string {
+	command := unquoteArg(p.StartCommand)
+	if fields := strings.Fields(command); len(fields) > 0 && !isShell(filepath.Base(fields[0])) {
+		return command
+	}
+
+	if isShell(p.CurrentCommand) {
+		return ""
+	}
+	return p.CurrentCommand
+}
+
+// Removes the double quotes tmux puts around an argument holding spaces,
+// along with the backslashes escaping the characters inside, i.e. \$ and \".
+func unquoteArg(arg string) string {
+	if len(arg) < 2 || arg[0] != '"' || arg[len(arg)-1] != '"' {
+		return arg
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range arg[1 : len(arg)-1] {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Checks if the command is a shell.
+func isShell(command string) bool {
+	switch strings.TrimPrefix(command, "-") {
+	case "sh", "bash", "zsh", "fish", "dash", "ksh", "tcsh", "csh", "nu":
+		return true
+	}
+	return false
+}
+
+// Resolves a start directory relative to the root, empty when both are.
+func joinRoot(root string, dir string) string {
+	dir = expandHome(dir)
+	if dir == "" {
+		return root
+	}
+	if filepath.IsAbs(dir) || root == "" {
+		return dir
+	}
+
+	return filepath.Join(root, dir)
+}
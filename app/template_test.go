@@ -0,0 +1,212 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"dev.yaml":    "root: /srv/app\nwindows:\n  - name: editor\n    panes:\n      - command: vim\n",
+		"ops.toml":    "name = \"operations\"\n[[windows]]\nname = \"logs\"\n",
+		"bad.yml":     "windows:\n  - panes:\n      - split: diagonal\n",
+		"notes.txt":   "not a template",
+		"broken.yaml": "windows: [",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, errs := loadTemplates(dir)
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want bad.yml and broken.yaml", errs)
+	}
+
+	names := make([]string, 0)
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"dev", "operations"}) {
+		t.Fatalf("templates = %v, want [dev operations]", names)
+	}
+}
+
+func TestLoadTemplatesMissingDir(t *testing.T) {
+	templates, errs := loadTemplates(filepath.Join(t.TempDir(), "templates"))
+	if templates != nil || errs != nil {
+		t.Fatalf("templates = %v, errors = %v, want none", templates, errs)
+	}
+}
+
+func TestPickTemplateNone(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	f := newFakeBackend()
+	newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	// not an error, the path to add the templates to is shown
+	a.pickTemplate()
+	if !a.ui.root.HasPage(modalName) {
+		t.Fatal("expected a message about the missing templates")
+	}
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	f := newFakeBackend()
+	a := newTestApp(t, f)
+
+	tmpl := &template{
+		Root: "/srv/app",
+		Env:  map[string]string{"APP_ENV": "development"},
+		Windows: []windowTemplate{
+			{
+				Name:  "editor",
+				Panes: []paneTemplate{{Command: "vim"}},
+			},
+			{
+				Name:   "server",
+				Layout: "even-horizontal",
+				Active: true,
+				Panes: []paneTemplate{
+					{Command: "npm run dev"},
+					{Root: "logs", Split: "horizontal"},
+				},
+			},
+		},
+	}
+
+	session, err := a.createFromTemplate(tmpl, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.env[session.Id]["APP_ENV"] != "development" {
+		t.Fatalf("env = %v, want APP_ENV set", f.env[session.Id])
+	}
+
+	windows, err := f.ListWindows(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[0].Name != "editor" || windows[1].Name != "server" {
+		t.Fatalf("windows = %v, want [editor server]", windows)
+	}
+	if windows[0].Active || !windows[1].Active {
+		t.Fatal("the server window should be selected")
+	}
+	if windows[1].Layout != "even-horizontal" {
+		t.Fatalf("layout = %q, want even-horizontal", windows[1].Layout)
+	}
+
+	panes, err := f.ListPanes(windows[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(panes) != 2 || panes[0].CurrentPath != "/srv/app" || panes[1].CurrentPath != "/srv/app/logs" {
+		t.Fatalf("panes = %v, want two panes in /srv/app and /srv/app/logs", panes)
+	}
+	if keys := f.keys[panes[0].Id]; !slices.Equal(keys, []string{"npm run dev", "Enter"}) {
+		t.Fatalf("keys = %v, want the command typed", keys)
+	}
+	if keys := f.keys[panes[1].Id]; len(keys) != 0 {
+		t.Fatalf("keys = %v, want none", keys)
+	}
+}
+
+func TestCreateFromTemplateFailure(t *testing.T) {
+	f := newFakeBackend()
+	a := newTestApp(t, f)
+
+	tmpl := &template{Windows: []windowTemplate{{Name: "main", Layout: "diagonal"}}}
+	session, err := a.createFromTemplate(tmpl, "dev")
+	if err == nil {
+		t.Fatal("expected the invalid layout to fail")
+	}
+	if session == nil || session.Name != "dev" {
+		t.Fatalf("session = %v, want the partially created session", session)
+	}
+}
+
+func TestJoinRoot(t *testing.T) {
+	for _, c := range []struct{ root, dir, want string }{
+		{"", "", ""},
+		{"/srv/app", "", "/srv/app"},
+		{"/srv/app", "logs", "/srv/app/logs"},
+		{"/srv/app", "/var/log", "/var/log"},
+		{"", "logs", "logs"},
+	} {
+		if got := joinRoot(c.root, c.dir); got != c.want {
+			t.Fatalf("joinRoot(%q, %q) = %q, want %q", c.root, c.dir, got, c.want)
+		}
+	}
+}
+
+func TestPaneCommand(t *testing.T) {
+	for _, c := range []struct {
+		start, current, want string
+	}{
+		{"", "bash", ""},
+		{"", "-zsh", ""},
+		{"", "vim", "vim"},
+		{`"npm run dev"`, "node", "npm run dev"},
+		{`"sleep 1 && echo \"done\""`, "bash", `sleep 1 && echo "done"`},
+		{`"sleep 1; echo \$HOME \\\\"`, "bash", `sleep 1; echo $HOME \\`},
+		{"htop", "htop", "htop"},
+		{"/bin/bash -l", "vim", "vim"},
+	} {
+		p := &gotmux.Pane{StartCommand: c.start, CurrentCommand: c.current}
+		if got := paneCommand(p); got != c.want {
+			t.Fatalf("paneCommand(%q, %q) = %q, want %q", c.start, c.current, got, c.want)
+		}
+	}
+}
+
+func TestSessionTemplate(t *testing.T) {
+	f := newFakeBackend()
+	session, err := f.NewSession(&gotmux.SessionOptions{Name: "dev", StartDirectory: "/srv/app"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := f.NewWindow(session, &gotmux.NewWindowOptions{WindowName: "server", StartDirectory: "/srv/app/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	panes, err := f.ListPanes(window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SplitPane(panes[0], &gotmux.SplitWindowOptions{StartDirectory: "/var/log", ShellCommand: "tail -f syslog"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SelectLayout(window, "even-horizontal"); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	tmpl, err := a.sessionTemplate(session, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "dev" || tmpl.Root != "/srv/app" || len(tmpl.Windows) != 2 {
+		t.Fatalf("template = %+v, want dev in /srv/app with 2 windows", tmpl)
+	}
+
+	wt := tmpl.Windows[1]
+	if wt.Name != "server" || wt.Layout != "even-horizontal" || wt.Active {
+		t.Fatalf("window = %+v, want the inactive server window", wt)
+	}
+	want := []paneTemplate{{Root: "api"}, {Root: "/var/log", Command: "tail -f syslog"}}
+	if !slices.Equal(wt.Panes, want) {
+		t.Fatalf("panes = %+v, want %+v", wt.Panes, want)
+	}
+}
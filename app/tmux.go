@@ -0,0 +1,372 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Backend is the set of tmux operations used by the views.
+// The gotmux values are only used as data, every action goes through the backend
+// so that the views can be driven by something other than a real tmux server.
+type Backend interface {
+	// sessions
+	ListSessions() ([]*gotmux.Session, error)
+	NewSession(op *gotmux.SessionOptions, env map[string]string) (*gotmux.Session, error)
+	RenameSession(session *gotmux.Session, name string) error
+	KillSession(session *gotmux.Session) error
+	AttachSession(session *gotmux.Session) error
+	DetachSession(session *gotmux.Session) error
+	SwitchClient(session *gotmux.Session) error
+
+	// windows
+	ListWindows(session *gotmux.Session) ([]*gotmux.Window, error)
+	NewWindow(session *gotmux.Session, op *gotmux.NewWindowOptions) (*gotmux.Window, error)
+	RenameWindow(window *gotmux.Window, name string) error
+	KillWindow(window *gotmux.Window) error
+	ListLinkedSessions(window *gotmux.Window) ([]*gotmux.Session, error)
+	GetWindow(id string) (*gotmux.Window, error)
+	SelectWindow(session *gotmux.Session, window *gotmux.Window) error
+	SelectLayout(window *gotmux.Window, layout string) error
+	MoveWindow(window *gotmux.Window, from *gotmux.Session, to *gotmux.Session) error
+	LinkWindow(window *gotmux.Window, session *gotmux.Session) error
+	UnlinkWindow(window *gotmux.Window, session *gotmux.Session) error
+	SwapWindows(session *gotmux.Session, window *gotmux.Window, other *gotmux.Window) error
+	RenumberWindows(session *gotmux.Session) error
+
+	// panes
+	ListPanes(window *gotmux.Window) ([]*gotmux.Pane, error)
+	SplitPane(pane *gotmux.Pane, op *gotmux.SplitWindowOptions) (*gotmux.Pane, error)
+	KillPane(pane *gotmux.Pane) error
+	SelectPane(pane *gotmux.Pane) error
+	SwapPanes(pane *gotmux.Pane, other *gotmux.Pane) error
+	ResizePane(pane *gotmux.Pane, direction string, cells int) error
+	ZoomPane(pane *gotmux.Pane) error
+	BreakPane(pane *gotmux.Pane, session *gotmux.Session) (*gotmux.Window, error)
+	RotatePanes(window *gotmux.Window, reverse bool) error
+	SendKeys(pane *gotmux.Pane, literal bool, keys ...string) error
+	CapturePane(pane *gotmux.Pane) (string, error)
+	CaptureHistory(pane *gotmux.Pane, lines int) (string, error)
+}
+
+// Backend implementation over a real tmux server using gotmux.
+type gotmuxBackend struct {
+	tmux *gotmux.Tmux
+}
+
+// Creates a backend connected to the default tmux server.
+func newGotmuxBackend() (*gotmuxBackend, error) {
+	tmux, err := gotmux.DefaultTmux()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gotmuxBackend{tmux: tmux}, nil
+}
+
+func (b *gotmuxBackend) ListSessions() ([]*gotmux.Session, error) {
+	sessions, err := b.tmux.ListSessions()
+	if err != nil && !b.serverRunning() {
+		// no server simply means there are no sessions yet
+		return []*gotmux.Session{}, nil
+	}
+
+	return sessions, err
+}
+
+func (b *gotmuxBackend) NewSession(op *gotmux.SessionOptions, env map[string]string) (*gotmux.Session, error) {
+	// gotmux quotes the shell command as a single word and discards the reason of failures
+	args := []string{"new-session", "-d", "-P", "-F", "#{session_id}"}
+	for name, value := range env {
+		args = append(args, "-e", name+"="+value)
+	}
+	if op != nil {
+		if op.Name != "" {
+			args = append(args, "-s", op.Name)
+		}
+		if op.StartDirectory != "" {
+			args = append(args, "-c", op.StartDirectory)
+		}
+		if op.Width != 0 {
+			args = append(args, "-x", strconv.Itoa(op.Width))
+		}
+		if op.Height != 0 {
+			args = append(args, "-y", strconv.Itoa(op.Height))
+		}
+		if op.ShellCommand != "" {
+			args = append(args, op.ShellCommand)
+		}
+	}
+
+	id, err := runTmux(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new session is the only one with its id
+	id = strings.TrimSpace(id)
+	sessions, err := b.tmux.ListSessions()
+	if err != nil {
+		return nil, b.check(err)
+	}
+	for _, session := range sessions {
+		if session.Id == id {
+			return session, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find session: %s", id)
+}
+
+func (b *gotmuxBackend) RenameSession(session *gotmux.Session, name string) error {
+	// gotmux drops the reason of the failure, i.e. a duplicate name
+	_, err := runTmux("rename-session", "-t", session.Id, name)
+	return err
+}
+
+func (b *gotmuxBackend) KillSession(session *gotmux.Session) error {
+	_, err := runTmux("kill-session", "-t", session.Id)
+	return err
+}
+
+func (b *gotmuxBackend) AttachSession(session *gotmux.Session) error {
+	return b.check(session.Attach())
+}
+
+func (b *gotmuxBackend) DetachSession(session *gotmux.Session) error {
+	// the read-only control client listening to the notifications is attached too, it is kept
+	out, err := runTmux("list-clients", "-t", session.Id, "-F", "#{client_control_mode}#{client_readonly} #{client_name}")
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		flags, name, ok := strings.Cut(line, " ")
+		if !ok || flags == "11" {
+			continue
+		}
+		if _, err := runTmux("detach-client", "-t", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *gotmuxBackend) SwitchClient(session *gotmux.Session) error {
+	_, err := runTmux("switch-client", "-t", session.Id)
+	return err
+}
+
+func (b *gotmuxBackend) ListWindows(session *gotmux.Session) ([]*gotmux.Window, error) {
+	windows, err := session.ListWindows()
+	return windows, b.check(err)
+}
+
+func (b *gotmuxBackend) NewWindow(session *gotmux.Session, op *gotmux.NewWindowOptions) (*gotmux.Window, error) {
+	window, err := session.NewWindow(op)
+	return window, b.check(err)
+}
+
+func (b *gotmuxBackend) RenameWindow(window *gotmux.Window, name string) error {
+	_, err := runTmux("rename-window", "-t", window.Id, name)
+	return err
+}
+
+func (b *gotmuxBackend) KillWindow(window *gotmux.Window) error {
+	_, err := runTmux("kill-window", "-t", window.Id)
+	return err
+}
+
+func (b *gotmuxBackend) ListLinkedSessions(window *gotmux.Window) ([]*gotmux.Session, error) {
+	sessions, err := window.ListLinkedSessions()
+	return sessions, b.check(err)
+}
+
+func (b *gotmuxBackend) GetWindow(id string) (*gotmux.Window, error) {
+	window, err := b.tmux.GetWindowById(id)
+	return window, b.check(err)
+}
+
+func (b *gotmuxBackend) SelectWindow(session *gotmux.Session, window *gotmux.Window) error {
+	// gotmux targets the window alone, which is ambiguous for windows linked in several sessions
+	_, err := runTmux("select-window", "-t", session.Id+":"+window.Id)
+	return err
+}
+
+func (b *gotmuxBackend) SelectLayout(window *gotmux.Window, layout string) error {
+	// the gotmux layout constants are not all valid
+	_, err := runTmux("select-layout", "-t", window.Id, layout)
+	return err
+}
+
+func (b *gotmuxBackend) MoveWindow(window *gotmux.Window, from *gotmux.Session, to *gotmux.Session) error {
+	// the source session tells which link of the window is moved,
+	// and the trailing colon moves it to the next free index of the session
+	_, err := runTmux("move-window", "-d", "-s", from.Id+":"+window.Id, "-t", to.Id+":")
+	return err
+}
+
+func (b *gotmuxBackend) LinkWindow(window *gotmux.Window, session *gotmux.Session) error {
+	_, err := runTmux("link-window", "-d", "-s", window.Id, "-t", session.Id+":")
+	return err
+}
+
+func (b *gotmuxBackend) UnlinkWindow(window *gotmux.Window, session *gotmux.Session) error {
+	// fails if the session is the only one of the window, which would destroy it
+	_, err := runTmux("unlink-window", "-t", session.Id+":"+window.Id)
+	return err
+}
+
+func (b *gotmuxBackend) SwapWindows(session *gotmux.Session, window *gotmux.Window, other *gotmux.Window) error {
+	// the indexes of linked windows differ between sessions
+	_, err := runTmux("swap-window", "-d", "-s", session.Id+":"+window.Id, "-t", session.Id+":"+other.Id)
+	return err
+}
+
+func (b *gotmuxBackend) RenumberWindows(session *gotmux.Session) error {
+	// renumbers the windows in order from the base index
+	_, err := runTmux("move-window", "-r", "-t", session.Id)
+	return err
+}
+
+func (b *gotmuxBackend) ListPanes(window *gotmux.Window) ([]*gotmux.Pane, error) {
+	panes, err := window.ListPanes()
+	return panes, b.check(err)
+}
+
+func (b *gotmuxBackend) SplitPane(pane *gotmux.Pane, op *gotmux.SplitWindowOptions) (*gotmux.Pane, error) {
+	// gotmux does not return the new pane, print its id instead
+	args := []string{"split-window", "-P", "-F", "#{pane_id}", "-t", pane.Id}
+	if op != nil {
+		if op.SplitDirection != "" {
+			args = append(args, string(op.SplitDirection))
+		}
+		if op.StartDirectory != "" {
+			args = append(args, "-c", op.StartDirectory)
+		}
+		if op.ShellCommand != "" {
+			args = append(args, op.ShellCommand)
+		}
+	}
+
+	id, err := runTmux(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	newPane, err := b.tmux.GetPaneById(strings.TrimSpace(id))
+	return newPane, b.check(err)
+}
+
+func (b *gotmuxBackend) KillPane(pane *gotmux.Pane) error {
+	_, err := runTmux("kill-pane", "-t", pane.Id)
+	return err
+}
+
+func (b *gotmuxBackend) SelectPane(pane *gotmux.Pane) error {
+	return b.check(pane.Select())
+}
+
+func (b *gotmuxBackend) SwapPanes(pane *gotmux.Pane, other *gotmux.Pane) error {
+	_, err := runTmux("swap-pane", "-d", "-s", pane.Id, "-t", other.Id)
+	return err
+}
+
+func (b *gotmuxBackend) ResizePane(pane *gotmux.Pane, direction string, cells int) error {
+	// the direction is one of the L, R, U and D flags
+	_, err := runTmux("resize-pane", "-t", pane.Id, "-"+direction, strconv.Itoa(cells))
+	return err
+}
+
+func (b *gotmuxBackend) ZoomPane(pane *gotmux.Pane) error {
+	// toggles the zoom of the window on the pane
+	_, err := runTmux("resize-pane", "-Z", "-t", pane.Id)
+	return err
+}
+
+func (b *gotmuxBackend) BreakPane(pane *gotmux.Pane, session *gotmux.Session) (*gotmux.Window, error) {
+	// the new window takes the next free index of the session
+	id, err := runTmux("break-pane", "-d", "-s", pane.Id, "-t", session.Id+":", "-P", "-F", "#{window_id}")
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := b.tmux.GetWindowById(strings.TrimSpace(id))
+	return window, b.check(err)
+}
+
+func (b *gotmuxBackend) RotatePanes(window *gotmux.Window, reverse bool) error {
+	// panes move down by default, like the prefix C-o binding
+	direction := "-D"
+	if reverse {
+		direction = "-U"
+	}
+	_, err := runTmux("rotate-window", direction, "-t", window.Id)
+	return err
+}
+
+func (b *gotmuxBackend) SendKeys(pane *gotmux.Pane, literal bool, keys ...string) error {
+	args := []string{"send-keys", "-t", pane.Id}
+	if literal {
+		args = append(args, "-l")
+	}
+
+	// the keys may start with a dash, i.e. a command with a flag
+	args = append(args, "--")
+	_, err := runTmux(append(args, keys...)...)
+	return err
+}
+
+func (b *gotmuxBackend) CapturePane(pane *gotmux.Pane) (string, error) {
+	content, err := pane.Capture()
+	return content, b.check(err)
+}
+
+func (b *gotmuxBackend) CaptureHistory(pane *gotmux.Pane, lines int) (string, error) {
+	// keep the colors and join the wrapped lines
+	start := "-"
+	if lines > 0 {
+		start = "-" + strconv.Itoa(lines)
+	}
+	return runTmux("capture-pane", "-p", "-e", "-J", "-S", start, "-t", pane.Id)
+}
+
+// Adds context to an error returned by gotmux, which discards the tmux output.
+func (b *gotmuxBackend) check(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if !b.serverRunning() {
+		return fmt.Errorf("%w: no tmux server running", err)
+	}
+
+	return err
+}
+
+// Checks if the tmux server is running.
+func (b *gotmuxBackend) serverRunning() bool {
+	return exec.Command("tmux", "list-sessions").Run() == nil
+}
+
+// Runs a tmux command that gotmux does not provide.
+// Unlike gotmux, the error holds the message printed by tmux.
+func runTmux(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("tmux", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", errors.New(args[0] + ": " + msg)
+	}
+
+	return string(out), nil
+}
@@ -2,7 +2,9 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/gdamore/tcell/v2"
@@ -11,11 +13,26 @@ import (
 
 type Tree struct {
 	*tview.TreeView
+
+	// backend to query tmux
+	backend Backend
+
+	// all the session nodes, including the ones hidden by the filter
+	sessionNodes []*tview.TreeNode
+
+	// case insensitive pattern the displayed nodes must match, empty to show everything
+	filter string
 }
 
 type TreeNode struct {
 	value any
 	typ   TreeNodeType
+
+	// all the children, including the ones hidden by the filter
+	children []*tview.TreeNode
+
+	// set if the node is a target of the bulk actions
+	marked bool
 }
 
 type TreeNodeType uint
@@ -30,16 +47,19 @@ func (a *App) initTree() {
 	// instantiate tree view
 	t := &Tree{}
 	t.TreeView = tview.NewTreeView()
+	t.backend = a.backend
 
 	// style
 	t.SetBackgroundColor(tcell.ColorNone)
 	t.SetTitle(surroundSpace("Tree"))
-	t.SetTitleColor(tcell.ColorBlue)
-	t.SetGraphicsColor(tcell.ColorLightYellow)
+	t.SetTitleColor(conf.theme.accent)
+	t.SetGraphicsColor(conf.theme.border)
 	t.SetBorder(true)
 
 	// build tree
-	t.build()
+	if err := t.build(); err != nil {
+		a.ui.error(err)
+	}
 
 	// set the root as the current node
 	root := t.GetRoot()
@@ -55,17 +75,20 @@ func (a *App) initTree() {
 				rune:    '?',
 				display: "?",
 			},
+			action:      "help",
 			description: "Toggle cheatsheet",
 			handler: func() {
 				a.ui.help(kh)
 			},
 		},
+		a.finderKeybinding(),
 		{
 			key: &Key{
 				key:     tcell.KeyRune,
 				rune:    'w',
 				display: "w",
 			},
+			action:      "toggle",
 			description: "Toggle collapse/expand",
 			handler: func() {
 				// get cur node and invert its expanded
@@ -79,13 +102,15 @@ func (a *App) initTree() {
 				rune:    'D',
 				display: "D",
 			},
+			action:      "kill",
 			description: "Kill item",
 			handler: func() {
-				// TODO: errror
-
 				// get current node
 				cur := t.GetCurrentNode()
 				node := unwrapNode(cur)
+				if node == nil {
+					return
+				}
 
 				a.ui.confirm("Are you sure you want to kill this "+node.name()+" ?", func(b bool) {
 					if !b {
@@ -93,16 +118,22 @@ func (a *App) initTree() {
 					}
 
 					// handle all cases to kill
+					var err error
 					switch node.typ {
 					case Session:
-						node.session().Kill()
+						err = a.backend.KillSession(node.session())
 					case Window:
-						node.window().Kill()
+						err = a.backend.KillWindow(node.window())
 					case Pane:
-						node.pane().Kill()
+						err = a.backend.KillPane(node.pane())
+					}
+					if err != nil {
+						a.ui.error(err)
 					}
 
-					t.sync()
+					if err := t.sync(); err != nil {
+						a.ui.error(err)
+					}
 				})
 			},
 		},
@@ -112,33 +143,29 @@ func (a *App) initTree() {
 				rune:    'a',
 				display: "a",
 			},
+			action:      "new-session",
 			description: "Create a new session",
 			handler: func() {
-				tmux, _ := gotmux.DefaultTmux()
-				a.ui.editor("New session name", "", func(s string) {
-					session, _ := tmux.NewSession(&gotmux.SessionOptions{
-						Name: s,
-					})
-
-					a.ui.Suspend(func() {
-						session.Attach()
-					})
-
-					t.sync()
-				})
+				a.newSession()
 			},
 		},
+		a.templateKeybinding(),
+		a.exportKeybinding(func() *gotmux.Session {
+			session, _ := t.ancestors(t.GetCurrentNode())
+			return session
+		}),
 		{
 			key: &Key{
 				key:     tcell.KeyRune,
 				rune:    'r',
 				display: "r",
 			},
+			action:      "rename",
 			description: "Rename this item (sessions and windows only)",
 			handler: func() {
 				cur := t.GetCurrentNode()
 				node := unwrapNode(cur)
-				if node.typ == Pane {
+				if node == nil || node.typ == Pane {
 					return
 				}
 
@@ -151,14 +178,20 @@ func (a *App) initTree() {
 				}
 
 				a.ui.editor("New "+node.name()+" name", existingName, func(s string) {
+					var err error
 					switch node.typ {
 					case Session:
-						node.session().Rename(s)
+						err = a.backend.RenameSession(node.session(), s)
 					case Window:
-						node.window().Rename(s)
+						err = a.backend.RenameWindow(node.window(), s)
+					}
+					if err != nil {
+						a.ui.error(err)
 					}
 
-					t.sync()
+					if err := t.sync(); err != nil {
+						a.ui.error(err)
+					}
 				})
 			},
 		},
@@ -168,12 +201,40 @@ func (a *App) initTree() {
 				rune:    'R',
 				display: "R",
 			},
+			action:      "refresh",
 			description: "Refresh",
 			handler: func() {
-				t.build()
+				if err := t.build(); err != nil {
+					a.ui.error(err)
+				}
 				t.GetRoot().CollapseAll().Expand()
 			},
 		},
+		{
+			key: &Key{
+				key:     tcell.KeyRune,
+				rune:    'f',
+				display: "f",
+			},
+			action:      "filter",
+			description: "Filter the tree",
+			handler: func() {
+				// prune the tree live as the pattern is typed
+				i := a.ui.editor("Filter", t.filter, t.setFilter)
+				i.SetChangedFunc(t.setFilter)
+			},
+		},
+		{
+			key: &Key{
+				key:     tcell.KeyEsc,
+				display: "esc",
+			},
+			action:      "clear-filter",
+			description: "Clear the filter",
+			handler: func() {
+				t.setFilter("")
+			},
+		},
 		{
 			key: &Key{
 				display: "Enter",
@@ -188,213 +249,611 @@ func (a *App) initTree() {
 		},
 	})
 
+	// window and pane creation
+	kh = append(kh, a.createKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// saving and restoring the sessions
+	kh = append(kh, a.stateKeybindings()...)
+
+	// custom commands of the config
+	kh = append(kh, a.commandKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// typing in the panes
+	kh = append(kh, a.sendKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// moving and linking windows between sessions
+	kh = append(kh, a.linkKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// reordering windows and panes
+	kh = append(kh, a.orderKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// layouts and sizes of the windows and panes
+	kh = append(kh, a.layoutKeybinding(func() *target {
+		return t.target(t.GetCurrentNode())
+	}))
+	kh = append(kh, a.paneLayoutKeybindings(func() *target {
+		return t.target(t.GetCurrentNode())
+	})...)
+
+	// history of the previewed pane, and typing in it live
+	kh = append(kh, a.historyKeybinding(), a.liveKeybinding())
+
+	// marks and bulk actions
+	kh = append(kh, a.markKeybindings(t)...)
+
+	// command palette
+	kh = append(kh, a.paletteKeybindings()...)
+
+	// apply the keys of the config
+	handle := a.bind("tree", "tree", t, kh)
+
 	// register the keybindings
 	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		kh.handle(event)
+		if handle(event) {
+			return nil
+		}
 		return event
 	})
 
 	// set the Enter selected keybinding (enter)
 	t.SetSelectedFunc(func(node *tview.TreeNode) {
 		n := unwrapNode(node)
+		if n == nil {
+			return
+		}
+
+		// land on the exact window and pane, in the session it is listed under
+		var err error
+		session, window := t.ancestors(node)
 		switch n.typ {
 		case Session:
-			a.ui.Suspend(func() {
-				n.session().Attach()
-			})
+			err = a.attach(n.session())
 		case Window:
-			linkedSessions, _ := n.window().ListLinkedSessions()
-			if len(linkedSessions) > 0 {
-				a.ui.Suspend(func() {
-					linkedSessions[0].Attach()
-				})
-			}
+			err = a.attachPane(session, n.window(), nil)
 		case Pane:
-
+			err = a.attachPane(session, window, n.pane())
+		}
+		if err != nil {
+			a.ui.error(err)
 		}
 	})
 
 	// set the changed function to display preview
 	t.SetChangedFunc(func(node *tview.TreeNode) {
-		// unwrape node
-		n := unwrapNode(node)
-
-		// cases for the node
-		switch n.typ {
-		case Session:
-			windows, _ := n.session().ListWindows()
-			if len(windows) == 0 {
-				return
-			}
-			window := windows[0]
-			panes, _ := window.ListPanes()
-			if len(panes) == 0 {
-				return
-			}
-
-			pane := panes[0]
-			a.preview.update(pane)
-		case Window:
-			panes, _ := n.window().ListPanes()
-			if len(panes) == 0 {
-				return
-			}
-			pane := panes[0]
-			a.preview.update(pane)
-		case Pane:
-			a.preview.update(n.pane())
+		window, pane, err := t.previewed(node)
+		if err != nil {
+			a.ui.error(err)
+			return
 		}
+		a.preview.show(window, pane)
 	})
 
 	a.tree = t
 }
 
+// Gets what to preview for the node: the layout of the window for windows,
+// and of the first window for sessions, or the pane for panes. Both are nil if there is nothing.
+func (t *Tree) previewed(node *tview.TreeNode) (*gotmux.Window, *gotmux.Pane, error) {
+	// unwrape node
+	n := unwrapNode(node)
+	if n == nil {
+		return nil, nil, nil
+	}
+
+	// cases for the node
+	switch n.typ {
+	case Session:
+		windows, err := t.backend.ListWindows(n.session())
+		if err != nil || len(windows) == 0 {
+			return nil, nil, err
+		}
+		return windows[0], nil, nil
+	case Window:
+		return n.window(), nil, nil
+	case Pane:
+		return nil, n.pane(), nil
+	}
+
+	return nil, nil, nil
+}
+
 // Builds tree from tmux data.
-func (t *Tree) build() {
+func (t *Tree) build() error {
 	// set the root node
 	root := tview.NewTreeNode("sessions")
 	t.SetRoot(root)
 	root.SetSelectable(false)
 
-	// get tmux client
-	tmux, _ := gotmux.DefaultTmux()
-
 	// get all sessions
-	sessions, _ := tmux.ListSessions()
+	sessions, err := t.backend.ListSessions()
+	if err != nil {
+		return err
+	}
 
+	children := make([]*tview.TreeNode, 0, len(sessions))
 	for _, s := range sessions {
 		// build tree sessionNode with session
-		sessionNode := t.buildNode(s)
+		sessionNode, err := t.buildNode(s)
+		if err != nil {
+			return err
+		}
 
 		// add the node to root
-		root.AddChild(sessionNode)
+		children = append(children, sessionNode)
 	}
+	t.setChildren(root, children, nil)
+
+	return nil
 }
 
 // Syncs tmux data to the tree and removes no longer existing items.
-func (t *Tree) sync() {
-	// get all sessions
-	tmux, _ := gotmux.DefaultTmux()
-	sessions, _ := tmux.ListSessions()
+// Existing nodes are kept (with their expansion state) and ordered as in tmux.
+func (t *Tree) sync() error {
+	return t.syncSessions(true)
+}
 
-	// build a session map for quick access
-	sessionMap := make(map[string]*gotmux.Session)
-	for _, session := range sessions {
-		sessionMap[session.Name] = session
+// Syncs the sessions of the tree.
+// If deep is false, the windows of the existing sessions are not synced.
+func (t *Tree) syncSessions(deep bool) error {
+	// get all sessions
+	sessions, err := t.backend.ListSessions()
+	if err != nil {
+		return err
 	}
 
-	// go over the sessions and verify if they exist
+	// index the existing session nodes for quick access
 	root := t.GetRoot()
-	for _, sessionNode := range root.GetChildren() {
-		// if the session doesnt exist remove it and continue (dont loop into windows)
-		internalSessionNode := unwrapNode(sessionNode)
-		session := internalSessionNode.session()
-		session = sessionMap[session.Name]
-		if session == nil {
-			root.RemoveChild(sessionNode)
+	sessionNodes := make(map[string]*tview.TreeNode)
+	for _, sessionNode := range t.children(root) {
+		sessionNodes[unwrapNode(sessionNode).session().Id] = sessionNode
+	}
+
+	children := make([]*tview.TreeNode, 0, len(sessions))
+	for _, session := range sessions {
+		// if the session is new, build it collapsed like the initial tree
+		sessionNode := sessionNodes[session.Id]
+		if sessionNode == nil {
+			sessionNode, err = t.buildNode(session)
+			if err != nil {
+				return err
+			}
+			sessionNode.CollapseAll()
+			children = append(children, sessionNode)
 			continue
 		}
 
-		// update session reference and text
+		// delete the session from the map to indicate that it is processed
+		delete(sessionNodes, session.Id)
+
+		// update session reference, text and windows
+		internalSessionNode := unwrapNode(sessionNode)
 		internalSessionNode.value = session
 		sessionNode.SetText(internalSessionNode.title())
+		if deep {
+			if err := t.syncSession(sessionNode, session, true); err != nil {
+				return err
+			}
+		}
 
-		// delete the session from the map to indicate that it is processed
-		delete(sessionMap, session.Name)
-
-		// build window map for this session
-		windows, _ := session.ListWindows()
-		windowMap := make(map[string]*gotmux.Window)
-		for _, w := range windows {
-			windowMap[w.Id] = w
-		}
-
-		// since this session still exists loop over children of if
-		for _, windowNode := range sessionNode.GetChildren() {
-			// if the window doesnt exist remove from this tree and continue
-			internalWindowNode := unwrapNode(windowNode)
-			window := internalWindowNode.window()
-			window = windowMap[window.Id]
-			if window == nil {
-				sessionNode.RemoveChild(windowNode)
-				continue
+		children = append(children, sessionNode)
+	}
+
+	// any session left in the map no longer exists
+	t.setChildren(root, children, sessionNodes)
+	return nil
+}
+
+// Syncs the windows of an existing session node.
+// If deep is false, the panes of the existing windows are not synced.
+func (t *Tree) syncSession(sessionNode *tview.TreeNode, session *gotmux.Session, deep bool) error {
+	windows, err := t.backend.ListWindows(session)
+	if err != nil {
+		return err
+	}
+
+	// index the existing window nodes
+	windowNodes := make(map[string]*tview.TreeNode)
+	for _, windowNode := range t.children(sessionNode) {
+		windowNodes[unwrapNode(windowNode).window().Id] = windowNode
+	}
+
+	children := make([]*tview.TreeNode, 0, len(windows))
+	for _, window := range windows {
+		// build new windows collapsed
+		windowNode := windowNodes[window.Id]
+		if windowNode == nil {
+			windowNode, err = t.buildWindowNode(window)
+			if err != nil {
+				return err
 			}
+			windowNode.Collapse()
+			children = append(children, windowNode)
+			continue
+		}
 
-			// update window ref and text
-			internalWindowNode.value = window
-			windowNode.SetText(internalWindowNode.title())
+		// delete the window from the map to mark it as processed
+		delete(windowNodes, window.Id)
 
-			// delete the window from the map to mark it as processed
-			delete(windowMap, window.Id)
+		// update window ref, text and panes
+		internalWindowNode := unwrapNode(windowNode)
+		internalWindowNode.value = window
+		windowNode.SetText(internalWindowNode.title())
+		if deep {
+			if err := t.syncWindow(windowNode, window); err != nil {
+				return err
+			}
+		}
 
-			// get panes for this window
-			panes, _ := window.ListPanes()
+		children = append(children, windowNode)
+	}
 
-			// build pane map
-			paneMap := make(map[string]*gotmux.Pane)
-			for _, p := range panes {
-				paneMap[p.Id] = p
+	t.setChildren(sessionNode, children, windowNodes)
+	return nil
+}
+
+// Syncs the panes of an existing window node.
+func (t *Tree) syncWindow(windowNode *tview.TreeNode, window *gotmux.Window) error {
+	panes, err := t.backend.ListPanes(window)
+	if err != nil {
+		return err
+	}
+
+	// index the existing pane nodes
+	paneNodes := make(map[string]*tview.TreeNode)
+	for _, paneNode := range t.children(windowNode) {
+		paneNodes[unwrapNode(paneNode).pane().Id] = paneNode
+	}
+
+	children := make([]*tview.TreeNode, 0, len(panes))
+	for _, pane := range panes {
+		paneNode := paneNodes[pane.Id]
+		if paneNode == nil {
+			children = append(children, newTreeNode(pane, Pane))
+			continue
+		}
+
+		// delete pane from map to mark as processed
+		delete(paneNodes, pane.Id)
+
+		// update pane ref and text
+		internalPaneNode := unwrapNode(paneNode)
+		internalPaneNode.value = pane
+		paneNode.SetText(internalPaneNode.title())
+		children = append(children, paneNode)
+	}
+
+	t.setChildren(windowNode, children, paneNodes)
+	return nil
+}
+
+// Sets the children of a node, then applies the filter to display them.
+// If the current node is part of the removed nodes, the parent becomes the current node.
+func (t *Tree) setChildren(parent *tview.TreeNode, children []*tview.TreeNode, removed map[string]*tview.TreeNode) {
+	cur := t.GetCurrentNode()
+	for _, node := range removed {
+		node.Walk(func(n, _ *tview.TreeNode) bool {
+			if n == cur {
+				t.SetCurrentNode(parent)
 			}
+			return true
+		})
+	}
 
-			for _, paneNode := range windowNode.GetChildren() {
-				// if this pane doesnot exist, remove it
-				internalPaneNode := unwrapNode(paneNode)
-				pane := internalPaneNode.pane()
-				pane = paneMap[pane.Id]
-				if pane == nil {
-					windowNode.RemoveChild(paneNode)
-					continue
-				}
+	if n := unwrapNode(parent); n != nil {
+		n.children = children
+	} else {
+		t.sessionNodes = children
+	}
+	t.applyFilter()
+}
+
+// Gets all the children of the node, including the ones hidden by the filter.
+func (t *Tree) children(node *tview.TreeNode) []*tview.TreeNode {
+	if n := unwrapNode(node); n != nil {
+		return n.children
+	}
+
+	return t.sessionNodes
+}
+
+// Sets the filter and prunes the tree, expanding it to show the matches.
+func (t *Tree) setFilter(filter string) {
+	t.filter = filter
+	t.applyFilter()
+	if filter != "" {
+		t.GetRoot().ExpandAll()
+	}
+}
 
-				// update pane ref and text
-				internalPaneNode.value = pane
-				paneNode.SetText(internalPaneNode.title())
+// Displays the nodes matching the filter, along with their ancestors and descendants,
+// and shows the filter in the title. If the current node gets hidden, its closest
+// visible ancestor becomes the current node.
+func (t *Tree) applyFilter() {
+	cur := t.GetCurrentNode()
+	path := t.GetPath(cur)
 
-				// delete pane from map to mark as processed
-				delete(paneMap, pane.Id)
+	// returns if the node is displayed, matched is set if an ancestor matched
+	var apply func(node *tview.TreeNode, matched bool) bool
+	apply = func(node *tview.TreeNode, matched bool) bool {
+		n := unwrapNode(node)
+		matched = matched || t.filter == "" || n != nil && n.matches(t.filter)
+		visible := make([]*tview.TreeNode, 0)
+		for _, child := range t.children(node) {
+			if apply(child, matched) {
+				visible = append(visible, child)
 			}
+		}
+		node.SetChildren(visible)
+		return matched || len(visible) > 0
+	}
+	apply(t.GetRoot(), false)
+
+	title := "Tree"
+	if t.filter != "" {
+		title += " (filter: " + tview.Escape(t.filter) + ")"
+	}
+	t.SetTitle(surroundSpace(title))
+
+	if cur == nil || t.GetPath(cur) != nil {
+		return
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		if t.GetPath(path[i]) != nil {
+			t.SetCurrentNode(path[i])
+			return
+		}
+	}
+}
+
+// Syncs the windows of the session with the id.
+func (t *Tree) syncSessionById(id string) error {
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		session := unwrapNode(sessionNode).session()
+		if session.Id == id {
+			return t.syncSession(sessionNode, session, false)
+		}
+	}
+
+	return nil
+}
+
+// Syncs the panes of the window with the id, in all the sessions it is linked to.
+func (t *Tree) syncWindowById(id string) error {
+	for _, windowNode := range t.windowNodes(id) {
+		if err := t.syncWindow(windowNode, unwrapNode(windowNode).window()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Adds the window with the id to the sessions it is linked to.
+func (t *Tree) addWindow(id string) error {
+	window, err := t.backend.GetWindow(id)
+	if err != nil || window == nil {
+		return err
+	}
 
-			// add any pane remaining
-			for _, p := range paneMap {
-				windowNode.AddChild(newTreeNode(p, Pane))
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		session := unwrapNode(sessionNode).session()
+		if !slices.Contains(window.LinkedSessionsList, session.Name) {
+			continue
+		}
+
+		if err := t.syncSession(sessionNode, session, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Removes the window with the id from the sessions it is no longer linked to,
+// tmux notifies the same way when a window is killed and when it is unlinked from a session.
+func (t *Tree) removeWindow(id string) {
+	// a window that is gone is linked to no session
+	var linked []string
+	if window, err := t.backend.GetWindow(id); err != nil {
+		logError(err)
+	} else if window != nil {
+		linked = window.LinkedSessionsList
+	}
+
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		if slices.Contains(linked, unwrapNode(sessionNode).session().Name) {
+			continue
+		}
+
+		children := make([]*tview.TreeNode, 0)
+		removed := make(map[string]*tview.TreeNode)
+		for _, windowNode := range t.children(sessionNode) {
+			if unwrapNode(windowNode).window().Id == id {
+				removed[id] = windowNode
+				continue
 			}
+			children = append(children, windowNode)
 		}
 
-		// add any remaining windows from the window map
-		for _, w := range windowMap {
-			sessionNode.AddChild(newTreeNode(w, Window))
+		if len(removed) > 0 {
+			t.setChildren(sessionNode, children, removed)
 		}
 	}
 
-	// post prcessing all existing sessions in the tree, add any that are missing
-	for _, s := range sessionMap {
-		root.AddChild(t.buildNode(s))
+	// the nodes left may no longer be shared
+	for _, windowNode := range t.windowNodes(id) {
+		n := unwrapNode(windowNode)
+		n.window().LinkedSessions = len(linked)
+		n.window().LinkedSessionsList = linked
+		windowNode.SetText(n.title())
 	}
 }
 
-func (t *Tree) buildNode(session *gotmux.Session) *tview.TreeNode {
+// Renames the session with the id.
+func (t *Tree) renameSession(id string, name string) {
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		n := unwrapNode(sessionNode)
+		if n.session().Id == id {
+			n.session().Name = name
+			sessionNode.SetText(n.title())
+		}
+	}
+	t.applyFilter()
+}
+
+// Renames the window with the id, in all the sessions it is linked to.
+func (t *Tree) renameWindow(id string, name string) {
+	for _, windowNode := range t.windowNodes(id) {
+		n := unwrapNode(windowNode)
+		n.window().Name = name
+		windowNode.SetText(n.title())
+	}
+	t.applyFilter()
+}
+
+// Gets the nodes of the window with the id, one per session it is linked to.
+func (t *Tree) windowNodes(id string) []*tview.TreeNode {
+	out := make([]*tview.TreeNode, 0)
+	for _, sessionNode := range t.children(t.GetRoot()) {
+		for _, windowNode := range t.children(sessionNode) {
+			if unwrapNode(windowNode).window().Id == id {
+				out = append(out, windowNode)
+			}
+		}
+	}
+
+	return out
+}
+
+func (t *Tree) buildNode(session *gotmux.Session) (*tview.TreeNode, error) {
 	// build root (session)
 	root := newTreeNode(session, Session)
 
 	// build out children (windows)
-	windows, _ := session.ListWindows()
+	windows, err := t.backend.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]*tview.TreeNode, 0, len(windows))
 	for _, w := range windows {
-		windowNode := newTreeNode(w, Window)
-		root.AddChild(windowNode)
-		panes, _ := w.ListPanes()
+		windowNode, err := t.buildWindowNode(w)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, windowNode)
+	}
+	t.setChildren(root, children, nil)
+
+	return root, nil
+}
+
+func (t *Tree) buildWindowNode(window *gotmux.Window) (*tview.TreeNode, error) {
+	windowNode := newTreeNode(window, Window)
+	panes, err := t.backend.ListPanes(window)
+	if err != nil {
+		return nil, err
+	}
+
+	// build out panes
+	children := make([]*tview.TreeNode, 0, len(panes))
+	for _, p := range panes {
+		children = append(children, newTreeNode(p, Pane))
+	}
+	t.setChildren(windowNode, children, nil)
 
-		// build out panes
-		for _, p := range panes {
-			paneNode := newTreeNode(p, Pane)
-			windowNode.AddChild(paneNode)
+	return windowNode, nil
+}
+
+// Finds the node of an item and expands its parents so that it is visible.
+// The pane id, or both the window and pane ids, are empty to find a window or a session.
+// Returns nil if the item is not in the tree.
+func (t *Tree) reveal(sessionId string, windowId string, paneId string) *tview.TreeNode {
+	// ids to match at every level, stopping at the first empty one
+	ids := []string{sessionId, windowId, paneId}
+	node := t.GetRoot()
+	path := []*tview.TreeNode{}
+	for _, id := range ids {
+		if id == "" {
+			break
+		}
+
+		var next *tview.TreeNode
+		for _, child := range t.children(node) {
+			if unwrapNode(child).id() == id {
+				next = child
+				break
+			}
 		}
+		if next == nil {
+			return nil
+		}
+
+		path = append(path, node)
+		node = next
 	}
 
-	return root
+	// clear the filter if it hides the item
+	if t.GetPath(node) == nil {
+		t.setFilter("")
+	}
+
+	for _, n := range path {
+		n.Expand()
+	}
+	return node
+}
+
+// Gets the item of the node along with its parents, nil for the root node.
+func (t *Tree) target(node *tview.TreeNode) *target {
+	n := unwrapNode(node)
+	if n == nil {
+		return nil
+	}
+
+	item := &target{}
+	item.session, item.window = t.ancestors(node)
+	if n.typ == Pane {
+		item.pane = n.pane()
+	}
+	return item
 }
 
+// Gets the session and window the node is listed under, including the node itself.
+// They are nil when the node is above that level.
+func (t *Tree) ancestors(node *tview.TreeNode) (*gotmux.Session, *gotmux.Window) {
+	var session *gotmux.Session
+	var window *gotmux.Window
+	for _, n := range t.GetPath(node) {
+		tn := unwrapNode(n)
+		switch {
+		case tn == nil:
+		case tn.typ == Session:
+			session = tn.session()
+		case tn.typ == Window:
+			window = tn.window()
+		}
+	}
+	return session, window
+}
+
+// Gets the internal node, nil for the root node.
 func unwrapNode(node *tview.TreeNode) *TreeNode {
-	return node.GetReference().(*TreeNode)
+	if node == nil {
+		return nil
+	}
+
+	tn, _ := node.GetReference().(*TreeNode)
+	return tn
 }
 
 func newTreeNode(v any, nodeType TreeNodeType) *tview.TreeNode {
@@ -404,17 +863,22 @@ func newTreeNode(v any, nodeType TreeNodeType) *tview.TreeNode {
 	wrapped := tview.NewTreeNode(tn.title())
 	wrapped.SetReference(tn)
 	wrapped.SetSelectable(true)
-	switch nodeType {
-	case Session:
-		wrapped.SetColor(tcell.ColorBlue)
-	case Window:
-		wrapped.SetColor(tcell.ColorBlue)
-	case Pane:
-		wrapped.SetColor(tcell.ColorLightGrey)
-	}
+	wrapped.SetColor(tn.color())
 	return wrapped
 }
 
+// Gets the color of the tree node, depending on its type and mark.
+func (t *TreeNode) color() tcell.Color {
+	switch {
+	case t.marked:
+		return conf.theme.mark
+	case t.typ == Pane:
+		return conf.theme.muted
+	default:
+		return conf.theme.accent
+	}
+}
+
 // Gets the name of the tree node.
 func (t *TreeNode) name() string {
 	switch t.typ {
@@ -443,6 +907,12 @@ func (t *TreeNode) title() string {
 		if w.Active {
 			active = "(active)"
 		}
+		if w.LinkedSessions > 1 {
+			active += "(linked)"
+		}
+		if w.ZoomedFlag {
+			active += "(zoomed)"
+		}
 		idx := strconv.Itoa(w.Index)
 		title = fmt.Sprintf("%s - %s %s", idx, w.Name, active)
 	case Pane:
@@ -454,7 +924,47 @@ func (t *TreeNode) title() string {
 		title = fmt.Sprintf("%s %s", p.CurrentCommand, active)
 	}
 
-	return trimStr(" "+title, 60)
+	if t.marked {
+		title = markPrefix + title
+	}
+	return trimStr(" "+title, conf.TitleWidth)
+}
+
+// Checks if the item of the node matches the filter, case insensitive.
+// Sessions and windows match by name, panes by command, path or title.
+func (t *TreeNode) matches(filter string) bool {
+	var fields []string
+	switch t.typ {
+	case Session:
+		fields = []string{t.session().Name}
+	case Window:
+		fields = []string{t.window().Name}
+	case Pane:
+		p := t.pane()
+		fields = []string{p.CurrentCommand, p.CurrentPath, p.Title}
+	}
+
+	filter = strings.ToLower(filter)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the tmux id of the item of the node.
+func (t *TreeNode) id() string {
+	switch t.typ {
+	case Session:
+		return t.session().Id
+	case Window:
+		return t.window().Id
+	case Pane:
+		return t.pane().Id
+	}
+
+	return ""
 }
 
 func (t *TreeNode) session() *gotmux.Session {
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Gets the ids of the sessions listed at the top of the tree, in order.
+func treeSessions(a *App) []string {
+	ids := make([]string, 0)
+	for _, node := range a.tree.children(a.tree.GetRoot()) {
+		ids = append(ids, unwrapNode(node).id())
+	}
+	return ids
+}
+
+func TestTreeBuild(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[1], &gotmux.NewWindowOptions{WindowName: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestApp(t, f)
+	assertNoModal(t, a)
+
+	got := treeSessions(a)
+	if len(got) != 2 || got[0] != sessions[0].Id || got[1] != sessions[1].Id {
+		t.Fatalf("sessions = %v, want [%s %s]", got, sessions[0].Id, sessions[1].Id)
+	}
+	if a.tree.reveal(sessions[1].Id, w.Id, "") == nil {
+		t.Fatalf("window %s not in the tree", w.Id)
+	}
+	if a.tree.reveal(sessions[0].Id, "@0", "%0") == nil {
+		t.Fatal("pane %0 not in the tree")
+	}
+}
+
+func TestTreeSync(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	// changes made outside of the app
+	if err := f.KillSession(sessions[0]); err != nil {
+		t.Fatal(err)
+	}
+	added := newTestSessions(t, f, "gamma")[0]
+	w, err := f.NewWindow(sessions[1], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.tree.sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := treeSessions(a)
+	if len(got) != 2 || got[0] != sessions[1].Id || got[1] != added.Id {
+		t.Fatalf("sessions = %v, want [%s %s]", got, sessions[1].Id, added.Id)
+	}
+	if a.tree.reveal(sessions[1].Id, w.Id, "") == nil {
+		t.Fatalf("window %s not synced", w.Id)
+	}
+}
+
+func TestTreeKill(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "", ""))
+	press(a, tcell.KeyRune, 'D')
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	left, err := f.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].Id != sessions[1].Id {
+		t.Fatalf("sessions left = %v, want [%s]", left, sessions[1].Id)
+	}
+	if got := treeSessions(a); len(got) != 1 || got[0] != sessions[1].Id {
+		t.Fatalf("tree sessions = %v, want [%s]", got, sessions[1].Id)
+	}
+}
+
+func TestTreeKillCancelled(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "", ""))
+	press(a, tcell.KeyRune, 'D')
+	press(a, tcell.KeyEscape, 0)
+
+	left, err := f.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 {
+		t.Fatalf("sessions left = %d, want 1", len(left))
+	}
+	if a.ui.GetFocus() != a.tree {
+		t.Fatal("tree not focused back")
+	}
+}
+
+func TestTreeRename(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha")
+	a := newTestApp(t, f)
+
+	a.tree.SetCurrentNode(a.tree.reveal(sessions[0].Id, "@0", ""))
+	press(a, tcell.KeyRune, 'r')
+	press(a, tcell.KeyCtrlU, 0)
+	typeText(a, "editor")
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	w, err := f.GetWindow("@0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "editor" {
+		t.Fatalf("window name = %q, want %q", w.Name, "editor")
+	}
+	if node := unwrapNode(a.tree.reveal(sessions[0].Id, "@0", "")); node.window().Name != "editor" {
+		t.Fatalf("tree window name = %q, want %q", node.window().Name, "editor")
+	}
+}
+
+// Gets the ids of the children displayed under the node, in order.
+func visibleChildren(node *tview.TreeNode) []string {
+	ids := make([]string, 0)
+	for _, child := range node.GetChildren() {
+		ids = append(ids, unwrapNode(child).id())
+	}
+	return ids
+}
+
+func TestTreeFilter(t *testing.T) {
+	f := newFakeBackend()
+	sessions := newTestSessions(t, f, "alpha", "beta")
+	w, err := f.NewWindow(sessions[1], &gotmux.NewWindowOptions{WindowName: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t, f)
+
+	press(a, tcell.KeyRune, 'f')
+	typeText(a, "LOGS")
+	press(a, tcell.KeyEnter, 0)
+	assertNoModal(t, a)
+
+	// the session is kept as the ancestor of the window, and the pane as its descendant
+	if got := visibleChildren(a.tree.GetRoot()); !slices.Equal(got, []string{sessions[1].Id}) {
+		t.Fatalf("sessions shown = %v, want [%s]", got, sessions[1].Id)
+	}
+	beta := a.tree.reveal(sessions[1].Id, "", "")
+	if got := visibleChildren(beta); !slices.Equal(got, []string{w.Id}) {
+		t.Fatalf("windows shown = %v, want [%s]", got, w.Id)
+	}
+	if got := visibleChildren(a.tree.reveal(sessions[1].Id, w.Id, "")); len(got) != 1 {
+		t.Fatalf("panes shown = %v, want the pane of %s", got, w.Id)
+	}
+
+	// the filter applies to the items synced after it was set
+	other, err := f.NewWindow(sessions[0], &gotmux.NewWindowOptions{WindowName: "build-logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTestSessions(t, f, "gamma")
+	if err := a.tree.sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := visibleChildren(a.tree.GetRoot()); !slices.Equal(got, []string{sessions[0].Id, sessions[1].Id}) {
+		t.Fatalf("sessions shown after sync = %v, want [%s %s]", got, sessions[0].Id, sessions[1].Id)
+	}
+	if got := visibleChildren(a.tree.reveal(sessions[0].Id, "", "")); !slices.Equal(got, []string{other.Id}) {
+		t.Fatalf("windows shown after sync = %v, want [%s]", got, other.Id)
+	}
+
+	press(a, tcell.KeyEscape, 0)
+	if a.tree.filter != "" {
+		t.Fatalf("filter = %q, want it cleared", a.tree.filter)
+	}
+	if got := visibleChildren(a.tree.GetRoot()); len(got) != 3 {
+		t.Fatalf("sessions shown = %v, want all", got)
+	}
+	if got := visibleChildren(beta); len(got) != 2 {
+		t.Fatalf("windows shown = %v, want all", got)
+	}
+}
+
+func TestTreeNodeMatches(t *testing.T) {
+	pane := &TreeNode{typ: Pane, value: &gotmux.Pane{CurrentCommand: "vim", CurrentPath: "/src/app", Title: "editor"}}
+	for filter, want := range map[string]bool{"VIM": true, "src": true, "edit": true, "bash": false} {
+		if got := pane.matches(filter); got != want {
+			t.Fatalf("pane matches %q = %v, want %v", filter, got, want)
+		}
+	}
+}
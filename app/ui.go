@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -14,13 +18,21 @@ type UI struct {
 
 	// root component (allowing for modals and other functionalities)
 	root *tview.Pages
+
+	// view focused before the modal opened, focused again when it closes
+	back tview.Primitive
+
+	// reports the errors in place of the modal when set, for the tests which run without the event loop
+	report func(err error)
 }
 
 const (
-	modalName         = "modal"
-	editorModalWidth  = 40
-	editorModalHeight = 5
-	stringLengthLimit = 30
+	modalName          = "modal"
+	editorModalWidth   = 40
+	editorModalHeight  = 5
+	errorModalMaxWidth = 80
+	finderModalWidth   = 80
+	finderModalHeight  = 20
 )
 
 func newUI() *UI {
@@ -33,10 +45,16 @@ func newUI() *UI {
 
 // Opens a cheatsheet
 func (ui *UI) help(keys KeybdindingHolder) {
-	t := newTable("Cheatsheet", []string{"Key", "------------ Description ------------"}, func(v *Keybinding) {})
+	// the rejected custom commands are not listed
+	keys = slices.DeleteFunc(slices.Clone(keys), func(k *Keybinding) bool {
+		return k.command != nil && k.handler == nil
+	})
+
+	t := newTable("Cheatsheet", []string{"Key", "Action", "------------ Description ------------"}, func(v *Keybinding) {})
 	t.setRows(keys, func(k *Keybinding) []*tview.TableCell {
 		return []*tview.TableCell{
 			tview.NewTableCell(k.key.display),
+			tview.NewTableCell(k.action),
 			tview.NewTableCell(k.description),
 		}
 	})
@@ -45,7 +63,7 @@ func (ui *UI) help(keys KeybdindingHolder) {
 		ui.closeModal()
 	})
 
-	c := center(t, 50, 24)
+	c := center(t, 70, 24)
 	ui.openModal(c)
 	ui.SetFocus(t)
 }
@@ -60,15 +78,16 @@ func (ui *UI) confirm(title string, done func(bool)) {
 	// set the style
 	t.SetBorder(true)
 	t.SetBackgroundColor(tcell.ColorNone)
-	t.SetBorderColor(tcell.ColorLightYellow)
-	t.SetTitleColor(tcell.ColorBlue)
+	t.SetBorderColor(conf.theme.border)
+	t.SetTitleColor(conf.theme.accent)
 
 	// set the text
 	t.SetText("\n[green]Enter[white] - Confim  |  [red]Escape[white] - Cancel")
 	t.SetTextAlign(tview.AlignCenter)
 
-	// set func to close on enter/esc
+	// set func to close on enter/esc, before calling back so that it can open another modal
 	t.SetDoneFunc(func(key tcell.Key) {
+		ui.closeModal()
 		switch key {
 		case tcell.KeyEnter:
 			// confirm
@@ -77,7 +96,6 @@ func (ui *UI) confirm(title string, done func(bool)) {
 			// cancel
 			done(false)
 		}
-		ui.closeModal()
 	})
 
 	// center with dimensions
@@ -85,20 +103,70 @@ func (ui *UI) confirm(title string, done func(bool)) {
 	ui.openModal(c)
 }
 
+// Opens a confirmation modal listing what is about to happen, one line per step.
+// The list scrolls when it does not fit, done is only called once confirmed.
+func (ui *UI) confirmPlan(title string, lines []string, done func()) {
+	// build the list of steps
+	l := tview.NewTextView()
+	l.SetText(strings.Join(lines, "\n"))
+	l.SetBackgroundColor(tcell.ColorNone)
+	l.SetTextColor(conf.theme.text)
+
+	// build the hint below the list
+	h := tview.NewTextView()
+	h.SetDynamicColors(true)
+	h.SetBackgroundColor(tcell.ColorNone)
+	h.SetText("[green]Enter[white] - Confim  |  [red]Escape[white] - Cancel")
+	h.SetTextAlign(tview.AlignCenter)
+
+	// set the style
+	f := tview.NewFlex().SetDirection(tview.FlexRow)
+	f.AddItem(l, 0, 1, true)
+	f.AddItem(h, 1, 0, false)
+	f.SetTitle(surroundSpace(title))
+	f.SetBorder(true)
+	f.SetBorderPadding(1, 0, 1, 1)
+	f.SetBackgroundColor(tcell.ColorNone)
+	f.SetBorderColor(conf.theme.border)
+	f.SetTitleColor(conf.theme.accent)
+
+	// set func to close on enter/esc, before calling back so that it can open another modal
+	l.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			ui.closeModal()
+			done()
+		case tcell.KeyEsc:
+			ui.closeModal()
+		}
+	})
+
+	// center with dimensions, the list taking at most the height of the finder
+	width := max(editorModalWidth, len(title)+6)
+	for _, line := range lines {
+		width = max(width, len(line)+6)
+	}
+	width = min(width, errorModalMaxWidth)
+	height := min(len(lines), finderModalHeight-5) + 5
+	c := center(f, width, height)
+	ui.openModal(c)
+}
+
 // Opens a single line editor modal.
-func (ui *UI) editor(title string, defaultVal string, done func(string)) {
+// The input is returned to further customize it.
+func (ui *UI) editor(title string, defaultVal string, done func(string)) *tview.InputField {
 	// build input field
 	i := tview.NewInputField()
 	i.SetTitle(surroundSpace(title))
 
 	// set styles
-	i.SetFieldBackgroundColor(tcell.ColorDimGray)
-	i.SetFieldTextColor(tcell.ColorWhite)
+	i.SetFieldBackgroundColor(conf.theme.field)
+	i.SetFieldTextColor(conf.theme.text)
 	i.SetBackgroundColor(tcell.ColorNone)
 	i.SetBorder(true)
-	i.SetBorderColor(tcell.ColorLightYellow)
+	i.SetBorderColor(conf.theme.border)
 	i.SetBorderPadding(1, 1, 1, 1)
-	i.SetTitleColor(tcell.ColorLightSteelBlue)
+	i.SetTitleColor(conf.theme.title)
 	i.SetText(defaultVal)
 
 	// set acceptance function
@@ -106,12 +174,12 @@ func (ui *UI) editor(title string, defaultVal string, done func(string)) {
 		return true
 	})
 
-	// call the passed function in the done func
+	// call the passed function in the done func, after closing so that it can open another modal
 	i.SetDoneFunc(func(key tcell.Key) {
+		ui.closeModal()
 		if key == tcell.KeyEnter {
 			done(i.GetText())
 		}
-		ui.closeModal()
 	})
 
 	// center the input
@@ -119,10 +187,280 @@ func (ui *UI) editor(title string, defaultVal string, done func(string)) {
 
 	// open the editor
 	ui.openModal(c)
+	return i
+}
+
+// Opens a form modal, styled like the other modals.
+// The returned text view is displayed below the form to show validation messages.
+func (ui *UI) form(title string, f *tview.Form, width int, height int) *tview.TextView {
+	// set the style of the form and its items
+	f.SetBackgroundColor(tcell.ColorNone)
+	f.SetFieldBackgroundColor(conf.theme.field)
+	f.SetFieldTextColor(conf.theme.text)
+	f.SetLabelColor(conf.theme.title)
+	f.SetButtonBackgroundColor(conf.theme.field)
+	f.SetButtonTextColor(conf.theme.text)
+	f.SetButtonsAlign(tview.AlignCenter)
+	f.SetBorderPadding(1, 0, 1, 1)
+
+	// build the message view
+	m := tview.NewTextView()
+	m.SetDynamicColors(true)
+	m.SetTextAlign(tview.AlignCenter)
+	m.SetBackgroundColor(tcell.ColorNone)
+
+	// assemble the modal
+	flex := tview.NewFlex()
+	flex.SetDirection(tview.FlexRow)
+	flex.AddItem(f, 0, 1, true)
+	flex.AddItem(m, 1, 0, false)
+	flex.SetTitle(surroundSpace(title))
+	flex.SetTitleColor(conf.theme.title)
+	flex.SetBorder(true)
+	flex.SetBorderColor(conf.theme.border)
+	flex.SetBorderPadding(0, 1, 0, 0)
+	flex.SetBackgroundColor(tcell.ColorNone)
+
+	// close on escape
+	f.SetCancelFunc(ui.closeModal)
+
+	c := center(flex, width, height)
+	ui.openModal(c)
+	ui.SetFocus(f)
+	return m
+}
+
+// Opens a fuzzy finder modal over the candidates, ranking them live as the query is typed.
+// The done func is called with the index of the chosen candidate and the key that chose it,
+// which is Enter or one of the extra keys. The labels are shown before the candidates but not matched, they can be nil.
+func (ui *UI) finder(title string, labels []string, candidates []string, keys []tcell.Key, done func(idx int, key tcell.Key)) {
+	// build the query input
+	i := tview.NewInputField()
+	i.SetLabel("> ")
+	i.SetLabelColor(conf.theme.border)
+	i.SetFieldBackgroundColor(tcell.ColorNone)
+	i.SetFieldTextColor(conf.theme.text)
+	i.SetBackgroundColor(tcell.ColorNone)
+
+	// build the result list
+	l := tview.NewList()
+	l.ShowSecondaryText(false)
+	l.SetHighlightFullLine(true)
+	l.SetBackgroundColor(tcell.ColorNone)
+	l.SetSelectedStyle(tcell.StyleDefault.Background(conf.theme.selection).Foreground(conf.theme.selectionText))
+	l.SetMainTextColor(conf.theme.text)
+
+	// rank the candidates on every change of the query
+	var matches []int
+	update := func(query string) {
+		matches = fuzzyFilter(query, candidates)
+		l.Clear()
+		for _, idx := range matches {
+			text := tview.Escape(candidates[idx])
+			if labels != nil {
+				text = fmt.Sprintf("[%s]%-9s[-]%s", conf.theme.border, labels[idx], text)
+			}
+			l.AddItem(text, "", 0, nil)
+		}
+	}
+	i.SetChangedFunc(update)
+	update("")
+
+	// keep the focus on the input, the list is driven from it
+	i.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		cur := l.GetCurrentItem()
+		switch k := event.Key(); {
+		case k == tcell.KeyUp || k == tcell.KeyCtrlK:
+			l.SetCurrentItem(max(cur-1, 0))
+			return nil
+		case k == tcell.KeyDown || k == tcell.KeyCtrlJ:
+			l.SetCurrentItem(min(cur+1, l.GetItemCount()-1))
+			return nil
+		case k == tcell.KeyEsc:
+			ui.closeModal()
+			return nil
+		case k == tcell.KeyEnter || slices.Contains(keys, k):
+			ui.closeModal()
+			if cur >= 0 && cur < len(matches) {
+				done(matches[cur], k)
+			}
+			return nil
+		}
+		return event
+	})
+
+	// assemble the modal
+	f := tview.NewFlex()
+	f.SetDirection(tview.FlexRow)
+	f.AddItem(i, 1, 0, true)
+	f.AddItem(l, 0, 1, false)
+	f.SetTitle(surroundSpace(title))
+	f.SetTitleColor(conf.theme.title)
+	f.SetBorder(true)
+	f.SetBorderColor(conf.theme.border)
+	f.SetBorderPadding(0, 0, 1, 1)
+	f.SetBackgroundColor(tcell.ColorNone)
+
+	c := center(f, finderModalWidth, finderModalHeight)
+	ui.openModal(c)
+	ui.SetFocus(i)
+}
+
+// Opens a modal listing the items, calling changed each time another item is highlighted, if it is set.
+// The done func is called with the chosen item on Enter, or with ok false on Escape.
+func (ui *UI) picker(title string, items []string, changed func(idx int), done func(idx int, ok bool)) {
+	// build the list
+	l := tview.NewList()
+	l.ShowSecondaryText(false)
+	l.SetHighlightFullLine(true)
+	l.SetBackgroundColor(tcell.ColorNone)
+	l.SetSelectedStyle(tcell.StyleDefault.Background(conf.theme.selection).Foreground(conf.theme.selectionText))
+	l.SetMainTextColor(conf.theme.text)
+	for _, item := range items {
+		l.AddItem(tview.Escape(item), "", 0, nil)
+	}
+
+	// set after adding the items, which changes the current one
+	if changed != nil {
+		l.SetChangedFunc(func(idx int, _ string, _ string, _ rune) {
+			changed(idx)
+		})
+	}
+
+	// close before calling back so that it can open another modal
+	l.SetSelectedFunc(func(idx int, _ string, _ string, _ rune) {
+		ui.closeModal()
+		done(idx, true)
+	})
+	l.SetDoneFunc(func() {
+		ui.closeModal()
+		done(l.GetCurrentItem(), false)
+	})
+
+	// set the style
+	l.SetTitle(surroundSpace(title))
+	l.SetTitleColor(conf.theme.title)
+	l.SetBorder(true)
+	l.SetBorderColor(conf.theme.border)
+	l.SetBorderPadding(0, 0, 1, 1)
+
+	width := max(editorModalWidth, len(title)+6)
+	for _, item := range items {
+		width = max(width, len(item)+4)
+	}
+	c := center(l, min(width, errorModalMaxWidth), len(items)+2)
+	ui.openModal(c)
+	ui.SetFocus(l)
+}
+
+// Opens a modal showing the text and passing the keys pressed to the keys func,
+// until it is closed with Enter or Escape.
+func (ui *UI) keyModal(title string, text string, keys func(event *tcell.EventKey)) {
+	// build view
+	t := tview.NewTextView()
+	t.SetTitle(surroundSpace(title))
+	t.SetDynamicColors(true)
+
+	// set the style
+	t.SetBorder(true)
+	t.SetBackgroundColor(tcell.ColorNone)
+	t.SetBorderColor(conf.theme.border)
+	t.SetTitleColor(conf.theme.title)
+
+	// set the text
+	t.SetText("\n" + text + "\n\n[" + conf.theme.border.String() + "]Enter/Escape[white] - Close")
+	t.SetTextAlign(tview.AlignCenter)
+
+	// the keys are all taken, the text does not scroll
+	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter, tcell.KeyEsc:
+			ui.closeModal()
+		default:
+			keys(event)
+		}
+		return nil
+	})
+
+	c := center(t, max(editorModalWidth, len(text)+6, len(title)+6), editorModalHeight+2)
+	ui.openModal(c)
+	ui.SetFocus(t)
+}
+
+// Reports an error to the user in a modal and writes it to the log.
+// Safe to call from handlers and from async tasks.
+func (ui *UI) error(err error) {
+	logError(err)
+	if ui.report != nil {
+		ui.report(err)
+		return
+	}
+
+	// the modal is opened from the refresher since queuing an update
+	// from the event loop would block, and a handler may still close its own modal after returning
+	ui.queue(func() {
+		ui.QueueUpdateDraw(func() {
+			ui.openError(err)
+		})
+	})
+}
+
+// Opens the error modal.
+func (ui *UI) openError(err error) {
+	ui.openMessage("Error", err.Error(), conf.theme.error)
+}
+
+// Opens a modal showing a message to the user.
+func (ui *UI) message(title string, msg string) {
+	ui.openMessage(title, msg, conf.theme.border)
+}
+
+// Opens a modal showing a message, with the border in the color.
+func (ui *UI) openMessage(title string, msg string, color tcell.Color) {
+	// build view
+	t := tview.NewTextView()
+	t.SetTitle(surroundSpace(title))
+	t.SetDynamicColors(true)
+	t.SetWordWrap(true)
+
+	// set the style
+	t.SetBorder(true)
+	t.SetBorderPadding(0, 0, 1, 1)
+	t.SetBackgroundColor(tcell.ColorNone)
+	t.SetBorderColor(color)
+	t.SetTitleColor(color)
+
+	// set the text
+	t.SetText("\n" + tview.Escape(msg) + "\n\n[" + color.String() + "]Enter/Escape[white] - Close")
+	t.SetTextAlign(tview.AlignCenter)
+
+	// close on enter/esc
+	t.SetDoneFunc(func(key tcell.Key) {
+		ui.closeModal()
+	})
+
+	// center with dimensions, wrapping long lines
+	lines := strings.Split(msg, "\n")
+	width := editorModalWidth
+	for _, line := range lines {
+		width = max(width, len(line)+6)
+	}
+	width = min(width, errorModalMaxWidth)
+	height := editorModalHeight + 1
+	for _, line := range lines {
+		height += 1 + len(line)/(width-4)
+	}
+	c := center(t, width, height)
+	ui.openModal(c)
 }
 
 // Opens a generic model around the passed primitive.
 func (ui *UI) openModal(v tview.Primitive) {
+	// a modal may replace another one, the view to focus back is the one below both
+	if !ui.root.HasPage(modalName) {
+		ui.back = ui.GetFocus()
+	}
+
 	// open modal by adding a page
 	ui.root.AddPage(modalName, v, true, true)
 	ui.root.ShowPage(modalName)
@@ -132,6 +470,12 @@ func (ui *UI) openModal(v tview.Primitive) {
 func (ui *UI) closeModal() {
 	// close modal by deleting the page
 	ui.root.RemovePage(modalName)
+
+	// the pages would focus the default view of the main page
+	if ui.back != nil {
+		ui.SetFocus(ui.back)
+		ui.back = nil
+	}
 }
 
 // Queues a refresh task.
@@ -144,6 +488,18 @@ type Table[T any] struct {
 	*tview.Table
 	colTitles []string
 	values    []*T
+
+	// identifies a value, used to keep the selection when the rows are reset
+	key func(*T) string
+
+	// when set, selection changes do not run the callback
+	silent bool
+
+	// keys of the marked values, targets of the bulk actions
+	marked map[string]bool
+
+	// builds the cells of a row, kept to redraw the rows when the marks change
+	col func(*T) []*tview.TableCell
 }
 
 // Returns a new table with defaults and configs.
@@ -151,6 +507,7 @@ func newTable[T any](title string, colTitles []string, onSelectionChanged func(v
 	// init table and set common attributes
 	t := &Table[T]{}
 	t.Table = tview.NewTable()
+	t.marked = make(map[string]bool)
 	t.SetTitle(surroundSpace(title))
 	t.SetBorder(true)
 
@@ -174,31 +531,31 @@ func newTable[T any](title string, colTitles []string, onSelectionChanged func(v
 	onFocus := func() {
 		// focused colors
 		s := tcell.StyleDefault.
-			Background(tcell.ColorLightCyan).
-			Foreground(tcell.ColorBlack)
+			Background(conf.theme.selection).
+			Foreground(conf.theme.selectionText)
 		t.SetSelectedStyle(s)
-		t.SetBorderColor(tcell.ColorLightYellow)
+		t.SetBorderColor(conf.theme.border)
 
 		// make table selectable when view is focused
 		t.SetSelectable(true, false)
 
 		// set title color when focused
-		t.SetTitleColor(tcell.ColorLightSteelBlue)
+		t.SetTitleColor(conf.theme.title)
 	}
 	onUnfocus := func() {
 		// unfocused colors
 		s := tcell.StyleDefault.
 			Background(tcell.ColorNone).
-			Foreground(tcell.ColorWhite)
+			Foreground(conf.theme.text)
 		t.SetSelectedStyle(s)
-		t.SetBorderColor(tcell.ColorWhite)
+		t.SetBorderColor(conf.theme.text)
 
 		// make table unselectable when unfosued
 		// this is because of styling
 		t.SetSelectable(false, false)
 
 		// set title color back to default
-		t.SetTitleColor(tcell.ColorWhite)
+		t.SetTitleColor(conf.theme.text)
 	}
 	t.SetFocusFunc(onFocus)
 	t.SetBlurFunc(onUnfocus)
@@ -215,6 +572,10 @@ func newTable[T any](title string, colTitles []string, onSelectionChanged func(v
 	// set the selection changed function
 	// this sets the default behavior and then calls the callback
 	t.SetSelectionChangedFunc(func(row, column int) {
+		if t.silent {
+			return
+		}
+
 		// if the first row, reselect 1 (dont allow title selection)
 		if row == 0 {
 			t.Select(1, 0)
@@ -223,6 +584,9 @@ func newTable[T any](title string, colTitles []string, onSelectionChanged func(v
 
 		// `row - 1` because the idx of the obj will start at 0
 		idx := row - 1
+		if idx >= len(t.values) {
+			return
+		}
 
 		// run the call back
 		onSelectionChanged(t.values[idx])
@@ -235,7 +599,7 @@ func newTable[T any](title string, colTitles []string, onSelectionChanged func(v
 func (t *Table[T]) setColTitles(titles []string) {
 	t.colTitles = titles
 	for idx, title := range t.colTitles {
-		t.SetCell(0, idx, tview.NewTableCell(title).SetTextColor(tcell.ColorWheat))
+		t.SetCell(0, idx, tview.NewTableCell(title).SetTextColor(conf.theme.header))
 	}
 }
 
@@ -256,14 +620,68 @@ func (t *Table[T]) getSelected() *T {
 
 // Sets all the rows in the table based on the values passed
 func (t *Table[T]) setRows(values []*T, col func(*T) []*tview.TableCell) {
+	// remember the selection to restore it after
+	selected := t.getSelected()
+
+	// values that are gone can't stay marked
+	marked := make(map[string]bool)
+	for _, val := range values {
+		if t.isMarked(val) {
+			marked[t.key(val)] = true
+		}
+	}
+	t.marked = marked
+
 	t.Clear()
 	for row, val := range values {
 		cols := col(val)
+		if len(cols) > 0 && t.isMarked(val) {
+			cols[0].SetText(markPrefix + cols[0].Text)
+			for _, cell := range cols {
+				cell.SetTextColor(conf.theme.mark)
+			}
+		}
 		for col, cell := range cols {
 			t.SetCell(row+1, col, cell)
 		}
 	}
 	t.values = values
+	t.col = col
+	t.restoreSelection(selected)
+}
+
+// Selects the row holding the previously selected value, or the closest row if it is gone.
+// This does not run the selection changed callback.
+func (t *Table[T]) restoreSelection(selected *T) {
+	row, _ := t.GetSelection()
+	if selected != nil && t.key != nil {
+		for idx, v := range t.values {
+			if t.key(v) == t.key(selected) {
+				row = idx + 1
+				break
+			}
+		}
+	}
+
+	t.silent = true
+	t.Select(max(1, min(row, len(t.values))), 0)
+	t.silent = false
+}
+
+// Selects the row holding the value with the key, running the selection changed callback.
+// Returns false if there is no such row.
+func (t *Table[T]) selectKey(key string) bool {
+	if t.key == nil {
+		return false
+	}
+
+	for idx, v := range t.values {
+		if t.key(v) == key {
+			t.Select(idx+1, 0)
+			return true
+		}
+	}
+	return false
 }
 
 // Overriding this method to reset the col titles.
@@ -278,6 +696,12 @@ type Keybinding struct {
 	handler     func()
 	key         *Key
 	description string
+
+	// name of the action in the config, bindings without one can't be remapped
+	action string
+
+	// custom command of the config run by the binding, nil for the built-in actions
+	command *command
 }
 
 // Key type for both handling event and displaying cheatsheet.
@@ -285,26 +709,96 @@ type Key struct {
 	display string
 	rune    rune
 	key     tcell.Key
+
+	// modifiers that must be held, only Alt for runes, and Ctrl and Shift for special keys are checked
+	mod tcell.ModMask
+
+	// following key of a multi-key sequence, i.e. the second g of "g g"
+	next *Key
 }
 
 // KeybdindingHolder holds many keybindings.
 type KeybdindingHolder []*Keybinding
 
-// Handles a event by finidng the binding that mataches this event.
-func (k KeybdindingHolder) handle(event *tcell.EventKey) {
-	for _, binding := range k {
-		if event.Key() == tcell.KeyRune {
-			// if key is rune then we check the run
-			if event.Rune() == binding.key.rune {
+// Creates the func handling the events of a view with the keybindings.
+// It keeps track of the keys typed so far of multi-key sequences,
+// and returns true if the event was consumed by one, so that the view ignores it.
+func (k KeybdindingHolder) handler() func(event *tcell.EventKey) bool {
+	// bindings whose sequence starts with the keys typed so far
+	var pending []*Keybinding
+	depth := 0
+
+	var handle func(event *tcell.EventKey) bool
+	handle = func(event *tcell.EventKey) bool {
+		candidates := pending
+		if depth == 0 {
+			candidates = k
+		}
+
+		matched := make([]*Keybinding, 0)
+		for _, binding := range candidates {
+			// bindings without handlers are only displayed in the cheatsheet
+			if binding.handler == nil {
+				continue
+			}
+
+			if key := binding.key.at(depth); key != nil && key.matches(event) {
+				matched = append(matched, binding)
+			}
+		}
+
+		// a sequence that does not continue starts over from this key
+		if len(matched) == 0 {
+			if depth == 0 {
+				return false
+			}
+			pending, depth = nil, 0
+			return handle(event)
+		}
+
+		inSequence := depth > 0
+		for _, binding := range matched {
+			if binding.key.at(depth+1) == nil {
+				pending, depth = nil, 0
 				binding.handler()
-				return
+				return inSequence
 			}
-		} else if event.Key() == binding.key.key {
-			// if key is not rune then we check the key
-			binding.handler()
-			return
 		}
+
+		// wait for the next key
+		pending = matched
+		depth++
+		return true
+	}
+
+	return handle
+}
+
+// Gets the nth key of the sequence starting with this key, nil if it is shorter.
+func (k *Key) at(n int) *Key {
+	for ; k != nil && n > 0; n-- {
+		k = k.next
+	}
+	return k
+}
+
+// Checks if the event is this key, regardless of the rest of the sequence.
+func (k *Key) matches(event *tcell.EventKey) bool {
+	if event.Key() != k.key {
+		return false
 	}
+
+	// shift is part of the rune and ctrl of the control keys
+	if k.key == tcell.KeyRune {
+		return event.Rune() == k.rune && event.Modifiers()&tcell.ModAlt == k.mod&tcell.ModAlt
+	}
+
+	// the modifiers of the special keys are reported, not the ones of the control characters
+	mask := tcell.ModAlt
+	if k.key >= tcell.KeyRune {
+		mask |= tcell.ModCtrl | tcell.ModShift
+	}
+	return event.Modifiers()&mask == k.mod&mask
 }
 
 // Refresher is to handle refresh tasks that are sent to the channel.
@@ -350,11 +844,14 @@ func center(p tview.Primitive, width, height int) *tview.Flex {
 }
 
 func setupTabs(tabs *tview.Pages, pages []string) {
-	curPage := 0
 	show := func(p int) {
 		tabs.SwitchToPage(pages[p])
 	}
 	tabs.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// the page may have been switched to from elsewhere
+		front, _ := tabs.GetFrontPage()
+		curPage := max(0, slices.Index(pages, front))
+
 		k := event.Key()
 		switch k {
 		case tcell.KeyRight:
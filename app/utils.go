@@ -1,9 +1,13 @@
 package app
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -19,7 +23,7 @@ func unixTime(t string) time.Time {
 }
 
 func timeFormat() string {
-	return "02 Jan 15:04"
+	return conf.TimeFormat
 }
 
 func trimStrBack(s string, n int) string {
@@ -52,10 +56,100 @@ func isKeyDown(event *tcell.EventKey) bool {
 
 var logger *log.Logger
 
-func initLogger(path string) {
+// Opens the log file. If it can't be opened the logs are discarded and the error is returned.
+func initLogger(path string) error {
+	logger = log.New(io.Discard, "", 0)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("can't open the log file: %w", err)
+	}
+
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
-		log.Panicln(err)
+		return fmt.Errorf("can't open the log file: %w", err)
 	}
 	logger = log.New(f, "", 0)
+	return nil
+}
+
+// Path of the log file, in the user cache directory.
+func logPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "tmuxman", "tmuxman.log")
+}
+
+// Writes the error to the log if the logger is initialized.
+func logError(err error) {
+	if logger == nil {
+		return
+	}
+
+	logger.Printf("%s error: %v\n", time.Now().Format(time.DateTime), err)
+}
+
+// Expands a leading ~ to the home directory of the user.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
+
+// Completes the path with the directories it can continue with.
+// Hidden directories are only listed when the last element of the path starts with a dot.
+func completeDir(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	dir, prefix := filepath.Split(path)
+	readDir := expandHome(dir)
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	out := make([]string, 0)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		out = append(out, dir+name+"/")
+	}
+	return out
+}
+
+// Path of the tmuxman config directory, ~/.config/tmuxman unless XDG_CONFIG_HOME is set.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "tmuxman")
+	}
+
+	return filepath.Join(expandHome("~"), ".config", "tmuxman")
+}
+
+// Path of the tmuxman data directory, ~/.local/share/tmuxman unless XDG_DATA_HOME is set.
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "tmuxman")
+	}
+
+	return filepath.Join(expandHome("~"), ".local", "share", "tmuxman")
 }
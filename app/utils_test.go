@@ -0,0 +1,34 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitLoggerFallback(t *testing.T) {
+	t.Cleanup(func() { logger = nil })
+
+	// the cache dir is a file, so the log dir can't be created in it
+	cache := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(cache, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := initLogger(filepath.Join(cache, "tmuxman", "tmuxman.log")); err == nil {
+		t.Fatal("expected an error")
+	}
+	if logger == nil {
+		t.Fatal("logger not set")
+	}
+	logError(errors.New("discarded"))
+
+	path := filepath.Join(t.TempDir(), "tmuxman", "tmuxman.log")
+	if err := initLogger(path); err != nil {
+		t.Fatal(err)
+	}
+	logError(errors.New("logged"))
+	if content, err := os.ReadFile(path); err != nil || len(content) == 0 {
+		t.Fatalf("log file = %q, %v, want the error", content, err)
+	}
+}
@@ -3,9 +3,11 @@ module tmuxman
 go 1.23.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/GianlucaP106/gotmux v0.2.0
 	github.com/gdamore/tcell/v2 v2.7.1
 	github.com/rivo/tview v0.0.0-20241016194538-c5e4fb24af13
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
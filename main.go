@@ -1,7 +1,24 @@
 package main
 
-import "tmuxman/app"
+import (
+	"flag"
+
+	"tmuxman/app"
+)
 
 func main() {
-	app.Start()
+	refresh := flag.Duration("refresh", app.DefaultRefreshInterval, "interval between automatic refreshes, 0 to disable")
+	popup := flag.Bool("popup", false, "when running inside tmux, open in a popup that closes after switching sessions")
+	save := flag.Bool("save", false, "save all the sessions to the state file and exit")
+	restore := flag.Bool("restore", false, "restore the sessions from the state file and exit")
+	scrollback := flag.Bool("scrollback", false, "save the scrollback of the panes along with the sessions")
+	flag.Parse()
+
+	app.Start(app.Options{
+		RefreshInterval: *refresh,
+		Popup:           *popup,
+		Save:            *save,
+		Restore:         *restore,
+		Scrollback:      *scrollback,
+	})
 }